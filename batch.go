@@ -0,0 +1,49 @@
+package flagsheet
+
+import "sort"
+
+// EvaluationRequest is one evaluation in a batch.
+type EvaluationRequest struct {
+	Key     string
+	Context EvaluationContext
+}
+
+// EvaluationResult is the outcome of one evaluation in a batch.
+// Err is set if that evaluation failed, in which case the detail has ReasonError.
+type EvaluationResult struct {
+	EvaluationDetail
+	Err error
+}
+
+// BatchEvaluate evaluates many features, or many entities, at once.
+// Results are in the order of the requests, and every evaluation sees the
+// same configuration even if a refresh happens meanwhile.
+func (f *flagSheet) BatchEvaluate(reqs []EvaluationRequest) []EvaluationResult {
+	snap := f.current.Load()
+	results := make([]EvaluationResult, len(reqs))
+	for i, req := range reqs {
+		d, err := f.evaluate(snap, req.Key, req.Context)
+		results[i] = EvaluationResult{EvaluationDetail: d, Err: err}
+	}
+	return results
+}
+
+// EvaluateAll evaluates every feature for an evaluation context.
+// Results are sorted by feature key.
+func (f *flagSheet) EvaluateAll(ectx EvaluationContext) ([]EvaluationResult, error) {
+	snap := f.current.Load()
+	if snap == nil {
+		return nil, ErrNotReady
+	}
+	keys := make([]string, 0, len(snap.fmap))
+	for key := range snap.fmap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	results := make([]EvaluationResult, len(keys))
+	for i, key := range keys {
+		d, err := f.evaluate(snap, key, ectx)
+		results[i] = EvaluationResult{EvaluationDetail: d, Err: err}
+	}
+	return results, nil
+}
@@ -0,0 +1,149 @@
+package flagsheet
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ChangeEvent describes how the configuration changed in a refresh.
+// Every list is sorted.
+type ChangeEvent struct {
+	PreviousVersion string
+	Version         string
+
+	AddedFeatures   []string
+	RemovedFeatures []string
+	// ChangedFeatures have a different definition, overrides or buckets,
+	// e.g. because a weight changed or a feature before them in the layer
+	// was resized.
+	ChangedFeatures []string
+
+	AddedLayers   []string
+	RemovedLayers []string
+	// ChangedLayers have a different version or legacy setting.
+	ChangedLayers []string
+}
+
+// Empty reports whether no feature or layer changed.
+func (e ChangeEvent) Empty() bool {
+	return len(e.AddedFeatures)+len(e.RemovedFeatures)+len(e.ChangedFeatures)+
+		len(e.AddedLayers)+len(e.RemovedLayers)+len(e.ChangedLayers) == 0
+}
+
+// Subscribe registers fn to be called after each refresh that loads a new
+// configuration, and returns a function that unregisters it.
+//
+// fn is called synchronously from the refreshing goroutine, in the order the
+// changes happen, so it should return quickly and must not call Refresh.
+func (f *flagSheet) Subscribe(fn func(ChangeEvent)) func() {
+	f.subsMu.Lock()
+	defer f.subsMu.Unlock()
+	if f.subs == nil {
+		f.subs = make(map[int]func(ChangeEvent))
+	}
+	id := f.nextSub
+	f.nextSub++
+	f.subs[id] = fn
+	return func() {
+		f.subsMu.Lock()
+		delete(f.subs, id)
+		f.subsMu.Unlock()
+	}
+}
+
+// notify sends the diff between two snapshots to the subscribers.
+func (f *flagSheet) notify(prev, next *snapshot) {
+	f.subsMu.Lock()
+	subs := make([]func(ChangeEvent), 0, len(f.subs))
+	for _, fn := range f.subs {
+		subs = append(subs, fn)
+	}
+	f.subsMu.Unlock()
+	if len(subs) == 0 {
+		return
+	}
+	e := diffSnapshots(prev, next)
+	if e.Empty() {
+		return
+	}
+	for _, fn := range subs {
+		fn(e)
+	}
+}
+
+func diffSnapshots(prev, next *snapshot) ChangeEvent {
+	e := ChangeEvent{
+		PreviousVersion: prev.version,
+		Version:         next.version,
+	}
+	for key, feature := range next.fmap {
+		old, ok := prev.fmap[key]
+		switch {
+		case !ok:
+			e.AddedFeatures = append(e.AddedFeatures, key)
+		case featureChanged(prev, next, old, feature):
+			e.ChangedFeatures = append(e.ChangedFeatures, key)
+		}
+	}
+	for key := range prev.fmap {
+		if _, ok := next.fmap[key]; !ok {
+			e.RemovedFeatures = append(e.RemovedFeatures, key)
+		}
+	}
+	for name, layer := range next.lmap {
+		old, ok := prev.lmap[name]
+		switch {
+		case !ok:
+			e.AddedLayers = append(e.AddedLayers, name)
+		case old.Version != layer.Version || old.Legacy != layer.Legacy:
+			e.ChangedLayers = append(e.ChangedLayers, name)
+		}
+	}
+	for name := range prev.lmap {
+		if _, ok := next.lmap[name]; !ok {
+			e.RemovedLayers = append(e.RemovedLayers, name)
+		}
+	}
+	for _, keys := range [][]string{
+		e.AddedFeatures, e.RemovedFeatures, e.ChangedFeatures,
+		e.AddedLayers, e.RemovedLayers, e.ChangedLayers,
+	} {
+		sort.Strings(keys)
+	}
+	return e
+}
+
+// featureChanged reports whether entities could see a different value for
+// the feature, comparing its definition, overrides and the buckets of its
+// targets and of the layer that it owns.
+func featureChanged(prev, next *snapshot, old, feature Feature) bool {
+	if old.LayerName != feature.LayerName || old.Type != feature.Type || old.Default != feature.Default ||
+		!reflect.DeepEqual(old.VariantMap, feature.VariantMap) || len(old.Targets) != len(feature.Targets) {
+		return true
+	}
+	for i, target := range feature.Targets {
+		oldTarget := old.Targets[i]
+		if target.Rule != oldTarget.Rule || !reflect.DeepEqual(target.VariantMap, oldTarget.VariantMap) {
+			return true
+		}
+		// reordering rows moves variants between buckets
+		if target.cnt != oldTarget.cnt ||
+			!reflect.DeepEqual(target.buckets[:target.cnt], oldTarget.buckets[:oldTarget.cnt]) {
+			return true
+		}
+	}
+	if !reflect.DeepEqual(prev.omap[feature.Key], next.omap[feature.Key]) {
+		return true
+	}
+	oldLayer, newLayer := prev.lmap[old.LayerName], next.lmap[feature.LayerName]
+	if oldLayer.Version != newLayer.Version || oldLayer.Legacy != newLayer.Legacy {
+		return true
+	}
+	for i := range newLayer.owners {
+		if (oldLayer.owners[i] == feature.Key) != (newLayer.owners[i] == feature.Key) ||
+			newLayer.owners[i] == feature.Key && oldLayer.buckets[i] != newLayer.buckets[i] {
+			return true
+		}
+	}
+	return false
+}
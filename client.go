@@ -2,32 +2,58 @@ package flagsheet
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Yiling-J/theine-go"
 	"github.com/bufbuild/connect-go"
+	otelconnect "github.com/bufbuild/connect-opentelemetry-go"
 	flagsheetv1 "github.com/stillmatic/flagsheet/gen/flagsheet/v1"
 	"github.com/stillmatic/flagsheet/gen/flagsheet/v1/flagsheetv1connect"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type flagQuery struct {
+	// Version is the configuration version last seen by Watch, so that
+	// evaluations cached before a change are no longer used.
+	Version  string
 	Feature  string
 	EntityID string
+	// Attributes is the JSON encoding of the evaluation attributes.
+	Attributes string
 }
 
 type FlagClient struct {
 	// flags is the feature flags client
 	flags flagsheetv1connect.FlagSheetServiceClient
 	// cache stores key value pairs with their result
-	cache    *theine.Cache[flagQuery, string]
+	cache    *theine.Cache[flagQuery, EvaluationDetail]
 	duration time.Duration
+	// cacheMu guards closing the cache, which panics if it is used afterwards.
+	cacheMu sync.RWMutex
+	closed  bool
+	// version is the configuration version from Watch.
+	version atomic.Value
+	// local evaluates features in process, see NewLocalFlagClient.
+	local *FlagSheet
+	// remote is the source of the local flag sheet, which Watch pushes
+	// streamed configurations to.
+	remote *RemoteSource
 }
 
 func NewFlagClient(flagsURL string) *FlagClient {
-	flagsClient := flagsheetv1connect.NewFlagSheetServiceClient(http.DefaultClient, flagsURL)
-	cache, err := theine.NewBuilder[flagQuery, string](1024).Build()
+	flagsClient := flagsheetv1connect.NewFlagSheetServiceClient(
+		http.DefaultClient,
+		flagsURL,
+		connect.WithInterceptors(otelconnect.NewInterceptor()),
+	)
+	cache, err := theine.NewBuilder[flagQuery, EvaluationDetail](1024).Build()
 	if err != nil {
 		panic(err)
 	}
@@ -38,25 +64,413 @@ func NewFlagClient(flagsURL string) *FlagClient {
 	}
 }
 
+// NewLocalFlagClient returns a client that downloads the configuration from
+// the server and evaluates features locally, with the same bucketing as the
+// server, so evaluations never leave the process. The configuration is
+// refreshed every interval, and on every change while Watch runs.
+// Call Close to stop refreshing.
+func NewLocalFlagClient(ctx context.Context, flagsURL string, interval time.Duration, opts ...Option) (*FlagClient, error) {
+	remote := NewRemoteSource(flagsURL)
+	local, err := NewFlagSheet(ctx, remote, interval, opts...)
+	if err != nil {
+		return nil, err
+	}
+	f := NewFlagClient(flagsURL)
+	f.local = local
+	f.remote = remote
+	return f, nil
+}
+
+// Close stops refreshing the configuration of a local client.
+// It is safe to call more than once, and evaluations afterwards skip the cache.
+func (f *FlagClient) Close(ctx context.Context) error {
+	f.cacheMu.Lock()
+	if !f.closed {
+		f.closed = true
+		f.cache.Close()
+	}
+	f.cacheMu.Unlock()
+	if f.local != nil {
+		return f.local.Close(ctx)
+	}
+	return nil
+}
+
 func (f *FlagClient) Evaluate(ctx context.Context, feature string, entityID string) (string, error) {
+	return f.EvaluateContext(ctx, feature, EvaluationContext{ID: &entityID})
+}
+
+// EvaluateContext evaluates a feature for an entity and the attributes used by targeting rules.
+func (f *FlagClient) EvaluateContext(ctx context.Context, feature string, ectx EvaluationContext) (string, error) {
+	d, err := f.EvaluateDetail(ctx, feature, ectx)
+	return string(d.Value), err
+}
+
+// EvaluateDetail evaluates a feature and returns the variant with its type.
+// The evaluation is recorded on the active span in ctx, see TraceEvaluation.
+func (f *FlagClient) EvaluateDetail(ctx context.Context, feature string, ectx EvaluationContext) (EvaluationDetail, error) {
+	d, err := f.evaluateDetail(ctx, feature, ectx)
+	if err == nil {
+		TraceEvaluation(ctx, d)
+	}
+	return d, err
+}
+
+func (f *FlagClient) evaluateDetail(ctx context.Context, feature string, ectx EvaluationContext) (EvaluationDetail, error) {
+	if f.local != nil {
+		return f.local.EvaluateDetail(feature, ectx)
+	}
+	var entityID string
+	if ectx.ID != nil {
+		entityID = *ectx.ID
+	}
+	attrs, err := structpb.NewStruct(ectx.Attributes)
+	if err != nil {
+		return EvaluationDetail{Key: feature, Reason: ReasonError}, fmt.Errorf("invalid attributes: %w", err)
+	}
+	// encoding/json sorts map keys, so equal attributes share a cache entry
+	attrsKey, err := json.Marshal(ectx.Attributes)
+	if err != nil {
+		return EvaluationDetail{Key: feature, Reason: ReasonError}, fmt.Errorf("invalid attributes: %w", err)
+	}
+	version, _ := f.version.Load().(string)
 	query := flagQuery{
-		Feature:  feature,
-		EntityID: entityID,
+		Version:    version,
+		Feature:    feature,
+		EntityID:   entityID,
+		Attributes: string(attrsKey),
 	}
-	val, ok := f.cache.Get(query)
-	// cache hit
-	if ok {
-		return val, nil
+	// anonymous entities get a random bucket on every evaluation, so they
+	// are not cached
+	anonymous := ectx.ID == nil
+	if !anonymous {
+		val, ok := f.cacheGet(query)
+		// cache hit
+		if ok {
+			return val, nil
+		}
 	}
 	// cache miss, call and set cache
 	req := connect.NewRequest(&flagsheetv1.EvaluateRequest{
-		Feature:  feature,
-		EntityId: entityID,
+		Feature:    feature,
+		EntityId:   entityID,
+		Attributes: attrs.Fields,
+		Anonymous:  anonymous,
 	})
 	res, err := f.flags.Evaluate(ctx, req)
 	if err != nil {
-		return "", fmt.Errorf("could not evaluate feature: %w", err)
+		return EvaluationDetail{Key: feature, Reason: ReasonError}, fmt.Errorf("could not evaluate feature: %w", remoteError(feature, err))
+	}
+	d := responseDetail(feature, res.Msg)
+	if !anonymous {
+		f.cacheSet(query, d)
+	}
+	return d, nil
+}
+
+// cacheGet looks up an evaluation, missing once the client is closed.
+func (f *FlagClient) cacheGet(query flagQuery) (EvaluationDetail, bool) {
+	f.cacheMu.RLock()
+	defer f.cacheMu.RUnlock()
+	if f.closed {
+		return EvaluationDetail{}, false
+	}
+	return f.cache.Get(query)
+}
+
+// cacheSet caches an evaluation, unless the client is closed.
+func (f *FlagClient) cacheSet(query flagQuery, d EvaluationDetail) {
+	f.cacheMu.RLock()
+	defer f.cacheMu.RUnlock()
+	if !f.closed {
+		f.cache.SetWithTTL(query, d, 1, f.duration)
+	}
+}
+
+// Explain evaluates a feature on the server, bypassing the cache, and returns
+// the variant with the metadata explaining how the entity got it.
+// Local clients explain their own evaluation.
+func (f *FlagClient) Explain(ctx context.Context, feature string, ectx EvaluationContext) (EvaluationDetail, error) {
+	if f.local != nil {
+		return f.local.Explain(feature, ectx)
+	}
+	var entityID string
+	if ectx.ID != nil {
+		entityID = *ectx.ID
+	}
+	attrs, err := structpb.NewStruct(ectx.Attributes)
+	if err != nil {
+		return EvaluationDetail{Key: feature, Reason: ReasonError}, fmt.Errorf("invalid attributes: %w", err)
+	}
+	res, err := f.flags.Explain(ctx, connect.NewRequest(&flagsheetv1.ExplainRequest{
+		Feature:    feature,
+		EntityId:   entityID,
+		Attributes: attrs.Fields,
+		Anonymous:  ectx.ID == nil,
+	}))
+	if err != nil {
+		return EvaluationDetail{Key: feature, Reason: ReasonError}, fmt.Errorf("could not explain feature: %w", remoteError(feature, err))
+	}
+	return EvaluationDetail{
+		Key:             feature,
+		Value:           FeatureValue(res.Msg.Variant),
+		Reason:          reasons[res.Msg.Reason],
+		Layer:           res.Msg.Layer,
+		LayerVersion:    int(res.Msg.LayerVersion),
+		Bucket:          int(res.Msg.Bucket),
+		Rule:            res.Msg.Rule,
+		SnapshotVersion: res.Msg.SnapshotVersion,
+	}, nil
+}
+
+// BatchEvaluate evaluates many features, or many entities, in one call,
+// bypassing the cache. Results are in the order of the requests, with
+// per-evaluation errors; the error is only set if the call itself failed.
+func (f *FlagClient) BatchEvaluate(ctx context.Context, reqs []EvaluationRequest) ([]EvaluationResult, error) {
+	results, err := f.batchEvaluate(ctx, reqs)
+	traceResults(ctx, results)
+	return results, err
+}
+
+func (f *FlagClient) batchEvaluate(ctx context.Context, reqs []EvaluationRequest) ([]EvaluationResult, error) {
+	if f.local != nil {
+		return f.local.BatchEvaluate(reqs), nil
+	}
+	msgs := make([]*flagsheetv1.EvaluateRequest, len(reqs))
+	for i, req := range reqs {
+		msg := &flagsheetv1.EvaluateRequest{Feature: req.Key, Anonymous: req.Context.ID == nil}
+		if req.Context.ID != nil {
+			msg.EntityId = *req.Context.ID
+		}
+		attrs, err := structpb.NewStruct(req.Context.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attributes for %s: %w", req.Key, err)
+		}
+		msg.Attributes = attrs.Fields
+		msgs[i] = msg
+	}
+	res, err := f.flags.BatchEvaluate(ctx, connect.NewRequest(&flagsheetv1.BatchEvaluateRequest{
+		Requests: msgs,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("could not evaluate features: %w", remoteError("", err))
+	}
+	return resultDetails(res.Msg.Results), nil
+}
+
+// EvaluateAll evaluates every feature for an evaluation context in one call,
+// bypassing the cache. Results are sorted by feature key.
+func (f *FlagClient) EvaluateAll(ctx context.Context, ectx EvaluationContext) ([]EvaluationResult, error) {
+	results, err := f.evaluateAll(ctx, ectx)
+	traceResults(ctx, results)
+	return results, err
+}
+
+func (f *FlagClient) evaluateAll(ctx context.Context, ectx EvaluationContext) ([]EvaluationResult, error) {
+	if f.local != nil {
+		return f.local.EvaluateAll(ectx)
+	}
+	var entityID string
+	if ectx.ID != nil {
+		entityID = *ectx.ID
+	}
+	attrs, err := structpb.NewStruct(ectx.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attributes: %w", err)
+	}
+	res, err := f.flags.EvaluateAll(ctx, connect.NewRequest(&flagsheetv1.EvaluateAllRequest{
+		EntityId:   entityID,
+		Attributes: attrs.Fields,
+		Anonymous:  ectx.ID == nil,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("could not evaluate features: %w", remoteError("", err))
+	}
+	return resultDetails(res.Msg.Results), nil
+}
+
+// traceResults records the successful evaluations on the active span in ctx.
+func traceResults(ctx context.Context, results []EvaluationResult) {
+	for _, r := range results {
+		if r.Err == nil {
+			TraceEvaluation(ctx, r.EvaluationDetail)
+		}
+	}
+}
+
+func resultDetails(msgs []*flagsheetv1.EvaluationResult) []EvaluationResult {
+	results := make([]EvaluationResult, len(msgs))
+	for i, msg := range msgs {
+		if msg.Error != "" || msg.Response == nil {
+			err := fmt.Errorf("could not evaluate feature %s: %s", msg.Feature, msg.Error)
+			if msg.MissingKey != nil {
+				err = fmt.Errorf("could not evaluate feature %s: %w", msg.Feature, keyError(msg.MissingKey))
+			}
+			results[i] = EvaluationResult{
+				EvaluationDetail: EvaluationDetail{Key: msg.Feature, Reason: ReasonError},
+				Err:              err,
+			}
+			continue
+		}
+		results[i] = EvaluationResult{EvaluationDetail: responseDetail(msg.Feature, msg.Response)}
+	}
+	return results
+}
+
+// Watch streams configuration changes from the server, so that cached
+// evaluations are dropped as soon as the flags change rather than when they
+// expire, and local clients reload their configuration right away.
+// It reconnects with backoff until ctx is done, so run it in its own
+// goroutine:
+//
+//	go client.Watch(ctx)
+func (f *FlagClient) Watch(ctx context.Context) error {
+	backoff := janitor{Interval: time.Second, MaxBackoff: 30 * time.Second}
+	failures := 0
+	for {
+		received, err := f.watch(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if received {
+			failures = 0
+		}
+		failures++
+		if err != nil {
+			log.Printf("failed to watch flags, reconnecting: %v", err)
+		}
+		select {
+		case <-time.After(backoff.delay(failures)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// watch follows one stream until it ends, and reports whether it received anything.
+func (f *FlagClient) watch(ctx context.Context) (bool, error) {
+	stream, err := f.flags.Watch(ctx, connect.NewRequest(&flagsheetv1.WatchRequest{}))
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close()
+	received := false
+	for stream.Receive() {
+		received = true
+		config := stream.Msg().GetConfig()
+		version := config.GetVersion()
+		f.version.Store(version)
+		if f.local != nil {
+			if _, loaded := f.local.Tables(); loaded != version {
+				// the stream carries the whole config, so don't download it again
+				f.remote.push(configTables(config))
+				if err := f.local.refresh(ctx); err != nil {
+					log.Printf("failed to refresh flags: %v", err)
+				}
+			}
+		}
+	}
+	return received, stream.Err()
+}
+
+// remoteError maps errors from the server back to the library's errors, so
+// that errors.Is works the same for local and remote evaluation.
+// feature is the evaluated feature, if there is a single one.
+func remoteError(feature string, err error) error {
+	var cerr *connect.Error
+	if !errors.As(err, &cerr) {
+		return err
+	}
+	switch cerr.Code() {
+	case connect.CodeUnavailable:
+		return fmt.Errorf("%w: %w", ErrNotReady, err)
+	case connect.CodeNotFound:
+		for _, detail := range cerr.Details() {
+			v, derr := detail.Value()
+			if mk, ok := v.(*flagsheetv1.MissingKey); derr == nil && ok {
+				return keyError(mk)
+			}
+		}
+		if feature != "" {
+			return &KeyError{Key: feature, Err: ErrFeatureNotFound}
+		}
+	}
+	return err
+}
+
+func keyError(mk *flagsheetv1.MissingKey) *KeyError {
+	if mk.Kind == flagsheetv1.MissingKey_KIND_LAYER {
+		return &KeyError{Key: mk.Key, Err: ErrLayerNotFound}
+	}
+	return &KeyError{Key: mk.Key, Err: ErrFeatureNotFound}
+}
+
+var reasons = map[flagsheetv1.Reason]Reason{
+	flagsheetv1.Reason_REASON_BUCKET:          ReasonBucket,
+	flagsheetv1.Reason_REASON_TARGETING_MATCH: ReasonTargetingMatch,
+	flagsheetv1.Reason_REASON_OVERRIDE:        ReasonOverride,
+	flagsheetv1.Reason_REASON_DEFAULT:         ReasonDefault,
+	flagsheetv1.Reason_REASON_ERROR:           ReasonError,
+}
+
+// responseDetail recovers the variant type from the typed value in a response.
+func responseDetail(feature string, res *flagsheetv1.EvaluateResponse) EvaluationDetail {
+	d := EvaluationDetail{
+		Key:    feature,
+		Value:  FeatureValue(res.Variant),
+		Type:   TypeString,
+		Reason: reasons[res.Reason],
+	}
+	switch res.Value.(type) {
+	case *flagsheetv1.EvaluateResponse_BoolValue:
+		d.Type = TypeBool
+	case *flagsheetv1.EvaluateResponse_IntValue:
+		d.Type = TypeInt
+	case *flagsheetv1.EvaluateResponse_FloatValue:
+		d.Type = TypeFloat
+	case *flagsheetv1.EvaluateResponse_JsonValue:
+		d.Type = TypeJSON
+	}
+	return d
+}
+
+// EvaluateBool evaluates a bool feature.
+// It returns def if the evaluation fails or lands on an empty bucket.
+func (f *FlagClient) EvaluateBool(ctx context.Context, feature string, ectx EvaluationContext, def bool) (bool, error) {
+	d, err := f.EvaluateDetail(ctx, feature, ectx)
+	if err != nil {
+		return def, err
+	}
+	return d.boolValue(def)
+}
+
+// EvaluateInt evaluates an int feature.
+// It returns def if the evaluation fails or lands on an empty bucket.
+func (f *FlagClient) EvaluateInt(ctx context.Context, feature string, ectx EvaluationContext, def int64) (int64, error) {
+	d, err := f.EvaluateDetail(ctx, feature, ectx)
+	if err != nil {
+		return def, err
+	}
+	return d.intValue(def)
+}
+
+// EvaluateFloat evaluates a float feature.
+// It returns def if the evaluation fails or lands on an empty bucket.
+func (f *FlagClient) EvaluateFloat(ctx context.Context, feature string, ectx EvaluationContext, def float64) (float64, error) {
+	d, err := f.EvaluateDetail(ctx, feature, ectx)
+	if err != nil {
+		return def, err
+	}
+	return d.floatValue(def)
+}
+
+// EvaluateJSON evaluates a json feature and decodes the variant into into.
+// The caller provides the default by filling into beforehand, which is left
+// untouched if the evaluation fails or lands on an empty bucket.
+func (f *FlagClient) EvaluateJSON(ctx context.Context, feature string, ectx EvaluationContext, into interface{}) error {
+	d, err := f.EvaluateDetail(ctx, feature, ectx)
+	if err != nil {
+		return err
 	}
-	f.cache.SetWithTTL(query, res.Msg.Variant, 1, f.duration)
-	return res.Msg.Variant, nil
+	return d.jsonValue(into)
 }
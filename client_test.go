@@ -0,0 +1,476 @@
+package flagsheet_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stillmatic/flagsheet"
+	flagsheetv1 "github.com/stillmatic/flagsheet/gen/flagsheet/v1"
+	"github.com/stillmatic/flagsheet/gen/flagsheet/v1/flagsheetv1connect"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// fakeServer answers every evaluation with the configured response,
+// and streams the configs sent to it to watchers.
+type fakeServer struct {
+	flagsheetv1connect.UnimplementedFlagSheetServiceHandler
+	mu        sync.Mutex
+	responses map[string]*flagsheetv1.EvaluateResponse
+	configs   chan *flagsheetv1.Config
+	config    *flagsheetv1.Config
+}
+
+func (s *fakeServer) set(feature string, res *flagsheetv1.EvaluateResponse) {
+	s.mu.Lock()
+	s.responses[feature] = res
+	s.mu.Unlock()
+}
+
+func (s *fakeServer) Evaluate(
+	_ context.Context,
+	req *connect.Request[flagsheetv1.EvaluateRequest],
+) (*connect.Response[flagsheetv1.EvaluateResponse], error) {
+	s.mu.Lock()
+	res, ok := s.responses[req.Msg.Feature]
+	s.mu.Unlock()
+	if !ok {
+		return nil, connect.NewError(connect.CodeNotFound, nil)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *fakeServer) BatchEvaluate(
+	ctx context.Context,
+	req *connect.Request[flagsheetv1.BatchEvaluateRequest],
+) (*connect.Response[flagsheetv1.BatchEvaluateResponse], error) {
+	msg := &flagsheetv1.BatchEvaluateResponse{}
+	for _, r := range req.Msg.Requests {
+		msg.Results = append(msg.Results, s.result(r.Feature, r.EntityId))
+	}
+	return connect.NewResponse(msg), nil
+}
+
+func (s *fakeServer) EvaluateAll(
+	ctx context.Context,
+	req *connect.Request[flagsheetv1.EvaluateAllRequest],
+) (*connect.Response[flagsheetv1.EvaluateAllResponse], error) {
+	s.mu.Lock()
+	var features []string
+	for feature := range s.responses {
+		features = append(features, feature)
+	}
+	s.mu.Unlock()
+	sort.Strings(features)
+	msg := &flagsheetv1.EvaluateAllResponse{}
+	for _, feature := range features {
+		msg.Results = append(msg.Results, s.result(feature, req.Msg.EntityId))
+	}
+	return connect.NewResponse(msg), nil
+}
+
+func (s *fakeServer) result(feature, entityID string) *flagsheetv1.EvaluationResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res, ok := s.responses[feature]
+	if !ok {
+		return &flagsheetv1.EvaluationResult{Feature: feature, EntityId: entityID, Error: "not found"}
+	}
+	return &flagsheetv1.EvaluationResult{Feature: feature, EntityId: entityID, Response: res}
+}
+
+func (s *fakeServer) Explain(
+	_ context.Context,
+	req *connect.Request[flagsheetv1.ExplainRequest],
+) (*connect.Response[flagsheetv1.ExplainResponse], error) {
+	res, ok := s.responses[req.Msg.Feature]
+	if !ok {
+		return nil, connect.NewError(connect.CodeNotFound, nil)
+	}
+	return connect.NewResponse(&flagsheetv1.ExplainResponse{
+		Variant:         res.Variant,
+		Reason:          res.Reason,
+		Layer:           "a",
+		LayerVersion:    1,
+		Bucket:          400,
+		SnapshotVersion: "v1",
+	}), nil
+}
+
+func (s *fakeServer) GetConfig(
+	_ context.Context,
+	_ *connect.Request[flagsheetv1.GetConfigRequest],
+) (*connect.Response[flagsheetv1.GetConfigResponse], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return connect.NewResponse(&flagsheetv1.GetConfigResponse{Config: s.config}), nil
+}
+
+func (s *fakeServer) setConfig(tables *flagsheet.Tables, version string) *flagsheetv1.Config {
+	rows := func(table [][]string) []*flagsheetv1.Row {
+		var msgs []*flagsheetv1.Row
+		for _, row := range table {
+			msgs = append(msgs, &flagsheetv1.Row{Cells: row})
+		}
+		return msgs
+	}
+	config := &flagsheetv1.Config{
+		Version:   version,
+		Flags:     rows(tables.Flags),
+		Layers:    rows(tables.Layers),
+		Overrides: rows(tables.Overrides),
+	}
+	s.mu.Lock()
+	s.config = config
+	s.mu.Unlock()
+	return config
+}
+
+func (s *fakeServer) Watch(
+	ctx context.Context,
+	_ *connect.Request[flagsheetv1.WatchRequest],
+	stream *connect.ServerStream[flagsheetv1.WatchResponse],
+) error {
+	for {
+		select {
+		case config := <-s.configs:
+			if err := stream.Send(&flagsheetv1.WatchResponse{Config: config}); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func newTestServer(t *testing.T, server flagsheetv1connect.FlagSheetServiceHandler) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle(flagsheetv1connect.NewFlagSheetServiceHandler(server))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func newTestClient(t *testing.T, server flagsheetv1connect.FlagSheetServiceHandler) *flagsheet.FlagClient {
+	t.Helper()
+	return flagsheet.NewFlagClient(newTestServer(t, server))
+}
+
+func TestClientTypedValues(t *testing.T) {
+	jv, err := structpb.NewValue(map[string]interface{}{"color": "red"})
+	assert.NoError(t, err)
+	server := &fakeServer{responses: map[string]*flagsheetv1.EvaluateResponse{
+		"enabled": {Variant: "true", Value: &flagsheetv1.EvaluateResponse_BoolValue{BoolValue: true}},
+		"limit":   {Variant: "42", Value: &flagsheetv1.EvaluateResponse_IntValue{IntValue: 42}},
+		"ratio":   {Variant: "0.5", Value: &flagsheetv1.EvaluateResponse_FloatValue{FloatValue: 0.5}},
+		"config":  {Variant: `{"color": "red"}`, Value: &flagsheetv1.EvaluateResponse_JsonValue{JsonValue: jv}},
+		"holdout": {Reason: flagsheetv1.Reason_REASON_DEFAULT},
+	}}
+	client := newTestClient(t, server)
+	ctx := context.Background()
+	ectx := flagsheet.EvaluationContext{ID: stringPtr("my_id")}
+
+	b, err := client.EvaluateBool(ctx, "enabled", ectx, false)
+	assert.NoError(t, err)
+	assert.True(t, b)
+	i, err := client.EvaluateInt(ctx, "limit", ectx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), i)
+	f, err := client.EvaluateFloat(ctx, "ratio", ectx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, f)
+	var cfg struct{ Color string }
+	assert.NoError(t, client.EvaluateJSON(ctx, "config", ectx, &cfg))
+	assert.Equal(t, "red", cfg.Color)
+
+	// defaults
+	d, err := client.EvaluateDetail(ctx, "holdout", ectx)
+	assert.NoError(t, err)
+	assert.Equal(t, flagsheet.ReasonDefault, d.Reason)
+	i, err = client.EvaluateInt(ctx, "holdout", ectx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), i)
+	b, err = client.EvaluateBool(ctx, "limit", ectx, true)
+	assert.Error(t, err)
+	assert.True(t, b)
+	f, err = client.EvaluateFloat(ctx, "missing", ectx, 2.5)
+	assert.Error(t, err)
+	assert.Equal(t, 2.5, f)
+}
+
+func TestClientBatchEvaluate(t *testing.T) {
+	server := &fakeServer{responses: map[string]*flagsheetv1.EvaluateResponse{
+		"enabled": {Variant: "true", Value: &flagsheetv1.EvaluateResponse_BoolValue{BoolValue: true}},
+		"my_key":  {Variant: "bar", Reason: flagsheetv1.Reason_REASON_BUCKET},
+	}}
+	client := newTestClient(t, server)
+	ctx := context.Background()
+	ectx := flagsheet.EvaluationContext{ID: stringPtr("my_id")}
+
+	results, err := client.BatchEvaluate(ctx, []flagsheet.EvaluationRequest{
+		{Key: "my_key", Context: ectx},
+		{Key: "missing", Context: ectx},
+		{Key: "enabled", Context: ectx},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 3) {
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, "bar", string(results[0].Value))
+		assert.Equal(t, flagsheet.ReasonBucket, results[0].Reason)
+		assert.Error(t, results[1].Err)
+		assert.Equal(t, flagsheet.ReasonError, results[1].Reason)
+		assert.Equal(t, flagsheet.TypeBool, results[2].Type)
+	}
+
+	all, err := client.EvaluateAll(ctx, ectx)
+	assert.NoError(t, err)
+	if assert.Len(t, all, 2) {
+		assert.Equal(t, "enabled", all[0].Key)
+		assert.Equal(t, "my_key", all[1].Key)
+	}
+}
+
+func TestClientExplain(t *testing.T) {
+	server := &fakeServer{responses: map[string]*flagsheetv1.EvaluateResponse{
+		"my_key": {Variant: "bar", Reason: flagsheetv1.Reason_REASON_BUCKET},
+	}}
+	client := newTestClient(t, server)
+	d, err := client.Explain(context.Background(), "my_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")})
+	assert.NoError(t, err)
+	assert.Equal(t, flagsheet.EvaluationDetail{
+		Key:             "my_key",
+		Value:           "bar",
+		Reason:          flagsheet.ReasonBucket,
+		Layer:           "a",
+		LayerVersion:    1,
+		Bucket:          400,
+		SnapshotVersion: "v1",
+	}, d)
+
+	_, err = client.Explain(context.Background(), "missing", flagsheet.EvaluationContext{})
+	assert.Error(t, err)
+}
+
+func TestClientWatch(t *testing.T) {
+	server := &fakeServer{
+		responses: map[string]*flagsheetv1.EvaluateResponse{"my_key": {Variant: "foo"}},
+		configs:   make(chan *flagsheetv1.Config),
+	}
+	client := newTestClient(t, server)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.Watch(ctx)
+	server.configs <- &flagsheetv1.Config{Version: "v1"}
+
+	fv, err := client.Evaluate(ctx, "my_key", "my_id")
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", fv)
+
+	// a new config version stops serving cached evaluations
+	server.set("my_key", &flagsheetv1.EvaluateResponse{Variant: "bar"})
+	server.configs <- &flagsheetv1.Config{Version: "v2"}
+	assert.Eventually(t, func() bool {
+		fv, err := client.Evaluate(ctx, "my_key", "my_id")
+		return err == nil && fv == "bar"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestLocalClient(t *testing.T) {
+	// no responses, so every evaluation must be local
+	server := &fakeServer{
+		responses: map[string]*flagsheetv1.EvaluateResponse{},
+		configs:   make(chan *flagsheetv1.Config),
+	}
+	server.setConfig(exampleTables(), "v1")
+	url := newTestServer(t, server)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tables, err := flagsheet.NewRemoteSource(url).Fetch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, exampleTables().Flags, tables.Flags)
+	assert.Equal(t, "v1", tables.Token)
+
+	client, err := flagsheet.NewLocalFlagClient(ctx, url, time.Hour)
+	assert.NoError(t, err)
+	defer client.Close(ctx)
+	fv, err := client.Evaluate(ctx, "my_key", "my_id")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", fv)
+	d, err := client.Explain(ctx, "my_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")})
+	assert.NoError(t, err)
+	assert.Equal(t, 400, d.Bucket)
+	assert.Equal(t, "v1", d.SnapshotVersion)
+
+	// watching applies the streamed configuration when it changes,
+	// without downloading it again
+	go client.Watch(ctx)
+	changed := exampleTables()
+	changed.Flags[2][2] = "baz"
+	config := server.setConfig(changed, "v2")
+	server.setConfig(exampleTables(), "v1")
+	server.configs <- config
+	assert.Eventually(t, func() bool {
+		fv, err := client.Evaluate(ctx, "my_key", "my_id")
+		return err == nil && fv == "baz"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestLocalClientExposures(t *testing.T) {
+	server := &fakeServer{responses: map[string]*flagsheetv1.EvaluateResponse{}}
+	server.setConfig(exampleTables(), "v1")
+	logger := &recordingLogger{}
+	ctx := context.Background()
+	client, err := flagsheet.NewLocalFlagClient(ctx, newTestServer(t, server), time.Hour,
+		flagsheet.WithExposureLogger(logger))
+	assert.NoError(t, err)
+	defer client.Close(ctx)
+
+	// local evaluations log exposures, explaining them doesn't
+	_, err = client.Evaluate(ctx, "my_key", "my_id")
+	assert.NoError(t, err)
+	_, err = client.Explain(ctx, "my_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")})
+	assert.NoError(t, err)
+	assert.Len(t, logger.exposures, 1)
+}
+
+func TestClientClose(t *testing.T) {
+	client := newTestClient(t, &fakeServer{responses: map[string]*flagsheetv1.EvaluateResponse{
+		"my_key": {Variant: "foo"},
+	}})
+	ctx := context.Background()
+	assert.NoError(t, client.Close(ctx))
+	assert.NoError(t, client.Close(ctx))
+	// evaluations still work after Close, without the cache
+	for i := 0; i < 2; i++ {
+		fv, err := client.Evaluate(ctx, "my_key", "my_id")
+		assert.NoError(t, err)
+		assert.Equal(t, "foo", fv)
+	}
+}
+
+// anonymousServer counts evaluations, which must be anonymous.
+type anonymousServer struct {
+	fakeServer
+	calls atomic.Int64
+}
+
+func (s *anonymousServer) Evaluate(
+	ctx context.Context,
+	req *connect.Request[flagsheetv1.EvaluateRequest],
+) (*connect.Response[flagsheetv1.EvaluateResponse], error) {
+	if !req.Msg.Anonymous || req.Msg.EntityId != "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, nil)
+	}
+	s.calls.Add(1)
+	return s.fakeServer.Evaluate(ctx, req)
+}
+
+func TestClientAnonymous(t *testing.T) {
+	server := &anonymousServer{fakeServer: fakeServer{responses: map[string]*flagsheetv1.EvaluateResponse{
+		"enabled": {Variant: "true", Value: &flagsheetv1.EvaluateResponse_BoolValue{BoolValue: true}},
+	}}}
+	client := newTestClient(t, server)
+	for i := 0; i < 3; i++ {
+		b, err := client.EvaluateBool(context.Background(), "enabled", flagsheet.EvaluationContext{}, false)
+		assert.NoError(t, err)
+		assert.True(t, b)
+	}
+	// anonymous evaluations are random, so they are not cached
+	assert.Equal(t, int64(3), server.calls.Load())
+}
+
+// errorServer fails every evaluation with the error configured for the feature.
+type errorServer struct {
+	fakeServer
+	errors map[string]*connect.Error
+}
+
+func (s *errorServer) Evaluate(
+	_ context.Context,
+	req *connect.Request[flagsheetv1.EvaluateRequest],
+) (*connect.Response[flagsheetv1.EvaluateResponse], error) {
+	return nil, s.errors[req.Msg.Feature]
+}
+
+func TestClientErrors(t *testing.T) {
+	layerErr := connect.NewError(connect.CodeNotFound, errors.New("layer a not found"))
+	detail, err := connect.NewErrorDetail(&flagsheetv1.MissingKey{
+		Kind: flagsheetv1.MissingKey_KIND_LAYER,
+		Key:  "a",
+	})
+	assert.NoError(t, err)
+	layerErr.AddDetail(detail)
+	client := newTestClient(t, &errorServer{errors: map[string]*connect.Error{
+		"missing": connect.NewError(connect.CodeNotFound, errors.New("feature missing not found")),
+		"layered": layerErr,
+		"loading": connect.NewError(connect.CodeUnavailable, errors.New("flag sheet is not loaded")),
+		"broken":  connect.NewError(connect.CodeInternal, errors.New("oops")),
+	}})
+	ctx := context.Background()
+	var kerr *flagsheet.KeyError
+
+	_, err = client.Evaluate(ctx, "missing", "my_id")
+	assert.ErrorIs(t, err, flagsheet.ErrFeatureNotFound)
+	if assert.ErrorAs(t, err, &kerr) {
+		assert.Equal(t, "missing", kerr.Key)
+	}
+	_, err = client.Evaluate(ctx, "layered", "my_id")
+	assert.ErrorIs(t, err, flagsheet.ErrLayerNotFound)
+	if assert.ErrorAs(t, err, &kerr) {
+		assert.Equal(t, "a", kerr.Key)
+	}
+	_, err = client.Evaluate(ctx, "loading", "my_id")
+	assert.ErrorIs(t, err, flagsheet.ErrNotReady)
+	_, err = client.Evaluate(ctx, "broken", "my_id")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, flagsheet.ErrFeatureNotFound)
+	assert.NotErrorIs(t, err, flagsheet.ErrNotReady)
+}
+
+func TestClientTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	server := &fakeServer{responses: map[string]*flagsheetv1.EvaluateResponse{
+		"my_key": {Variant: "bar", Reason: flagsheetv1.Reason_REASON_BUCKET},
+	}}
+	client := newTestClient(t, server)
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+	fv, err := client.Evaluate(ctx, "my_key", "my_id")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", fv)
+	span.End()
+
+	var request tracetest.SpanStub
+	var rpcs int
+	for _, s := range exporter.GetSpans() {
+		switch {
+		case s.Name == "request":
+			request = s
+		case s.Parent.SpanID() == span.SpanContext().SpanID():
+			rpcs++
+		}
+	}
+	assert.Equal(t, 1, rpcs, "the evaluate call has a client span")
+	if assert.Len(t, request.Events, 1) {
+		assert.Equal(t, "feature_flag", request.Events[0].Name)
+		assert.Contains(t, request.Events[0].Attributes, attribute.String("feature_flag.variant", "bar"))
+	}
+}
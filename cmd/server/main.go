@@ -2,18 +2,37 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/bufbuild/connect-go"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/Iwark/spreadsheet.v2"
 
 	grpchealth "github.com/bufbuild/connect-grpchealth-go"
+	otelconnect "github.com/bufbuild/connect-opentelemetry-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/stillmatic/flagsheet"
 	fsv1 "github.com/stillmatic/flagsheet/gen/flagsheet/v1"
 	"github.com/stillmatic/flagsheet/gen/flagsheet/v1/flagsheetv1connect"
+	"github.com/stillmatic/flagsheet/metrics"
+	"github.com/stillmatic/flagsheet/ofrep"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 const (
@@ -21,64 +40,609 @@ const (
 	flagSheetVersionValue = "v1"
 )
 
+var reasons = map[flagsheet.Reason]fsv1.Reason{
+	flagsheet.ReasonBucket:         fsv1.Reason_REASON_BUCKET,
+	flagsheet.ReasonTargetingMatch: fsv1.Reason_REASON_TARGETING_MATCH,
+	flagsheet.ReasonOverride:       fsv1.Reason_REASON_OVERRIDE,
+	flagsheet.ReasonDefault:        fsv1.Reason_REASON_DEFAULT,
+	flagsheet.ReasonError:          fsv1.Reason_REASON_ERROR,
+}
+
 type FlagSheetServer struct {
 	fs *flagsheet.FlagSheet
+	// done is closed when the server shuts down, to end open watches.
+	done <-chan struct{}
 }
 
 func (s *FlagSheetServer) Evaluate(
 	ctx context.Context,
 	req *connect.Request[fsv1.EvaluateRequest],
 ) (*connect.Response[fsv1.EvaluateResponse], error) {
-	fv, err := s.fs.Evaluate(req.Msg.Feature, &req.Msg.EntityId)
+	id, err := requestEntity(req.Msg.EntityId, req.Msg.Anonymous)
 	if err != nil {
-		return nil, connect.NewError(
-			http.StatusNotFound,
-			err,
-		)
+		return nil, err
 	}
-	res := connect.NewResponse(&fsv1.EvaluateResponse{
-		Variant: string(fv),
+	d, err := s.fs.EvaluateDetail(req.Msg.Feature, flagsheet.EvaluationContext{
+		ID:         id,
+		Attributes: attributes(req.Msg.Attributes),
 	})
+	if err != nil {
+		return nil, connectError(err)
+	}
+	flagsheet.TraceEvaluation(ctx, d)
+	msg, err := evaluateResponse(d)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	res := connect.NewResponse(msg)
 	res.Header().Set(flagSheetVersionKey, flagSheetVersionValue)
 	return res, nil
 }
 
-func ok(_ http.ResponseWriter, _ *http.Request) {}
+func (s *FlagSheetServer) BatchEvaluate(
+	ctx context.Context,
+	req *connect.Request[fsv1.BatchEvaluateRequest],
+) (*connect.Response[fsv1.BatchEvaluateResponse], error) {
+	reqs := make([]flagsheet.EvaluationRequest, len(req.Msg.Requests))
+	for i, r := range req.Msg.Requests {
+		id, err := requestEntity(r.EntityId, r.Anonymous)
+		if err != nil {
+			return nil, err
+		}
+		reqs[i] = flagsheet.EvaluationRequest{
+			Key: r.Feature,
+			Context: flagsheet.EvaluationContext{
+				ID:         id,
+				Attributes: attributes(r.Attributes),
+			},
+		}
+	}
+	results := s.fs.BatchEvaluate(reqs)
+	for _, r := range results {
+		// if one failed because nothing is loaded, they all did
+		if errors.Is(r.Err, flagsheet.ErrNotReady) {
+			return nil, connectError(r.Err)
+		}
+	}
+	msg := &fsv1.BatchEvaluateResponse{
+		Results: make([]*fsv1.EvaluationResult, len(results)),
+	}
+	for i, r := range results {
+		msg.Results[i] = resultProto(r, req.Msg.Requests[i].EntityId)
+		if r.Err == nil {
+			flagsheet.TraceEvaluation(ctx, r.EvaluationDetail)
+		}
+	}
+	res := connect.NewResponse(msg)
+	res.Header().Set(flagSheetVersionKey, flagSheetVersionValue)
+	return res, nil
+}
 
-func main() {
-	service, err := flagsheet.NewSpreadsheetServiceFromEnv(context.Background())
+func (s *FlagSheetServer) EvaluateAll(
+	ctx context.Context,
+	req *connect.Request[fsv1.EvaluateAllRequest],
+) (*connect.Response[fsv1.EvaluateAllResponse], error) {
+	id, err := requestEntity(req.Msg.EntityId, req.Msg.Anonymous)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	results, err := s.fs.EvaluateAll(flagsheet.EvaluationContext{
+		ID:         id,
+		Attributes: attributes(req.Msg.Attributes),
+	})
+	if err != nil {
+		return nil, connectError(err)
+	}
+	msg := &fsv1.EvaluateAllResponse{
+		Results: make([]*fsv1.EvaluationResult, len(results)),
+	}
+	for i, r := range results {
+		msg.Results[i] = resultProto(r, req.Msg.EntityId)
+		if r.Err == nil {
+			flagsheet.TraceEvaluation(ctx, r.EvaluationDetail)
+		}
+	}
+	res := connect.NewResponse(msg)
+	res.Header().Set(flagSheetVersionKey, flagSheetVersionValue)
+	return res, nil
+}
+
+// connectError maps library errors to connect codes, and identifies the
+// missing key of NotFound errors with a MissingKey detail.
+func connectError(err error) *connect.Error {
+	if errors.Is(err, flagsheet.ErrNotReady) {
+		return connect.NewError(connect.CodeUnavailable, err)
+	}
+	mk := missingKey(err)
+	if mk == nil {
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	cerr := connect.NewError(connect.CodeNotFound, err)
+	if detail, derr := connect.NewErrorDetail(mk); derr == nil {
+		cerr.AddDetail(detail)
+	}
+	return cerr
+}
+
+// missingKey returns what was not found, or nil for other errors.
+func missingKey(err error) *fsv1.MissingKey {
+	var kerr *flagsheet.KeyError
+	if !errors.As(err, &kerr) {
+		return nil
+	}
+	kind := fsv1.MissingKey_KIND_FEATURE
+	if errors.Is(kerr, flagsheet.ErrLayerNotFound) {
+		kind = fsv1.MissingKey_KIND_LAYER
+	}
+	return &fsv1.MissingKey{Kind: kind, Key: kerr.Key}
+}
+
+// evaluateResponse converts an evaluation to its wire format.
+func evaluateResponse(d flagsheet.EvaluationDetail) (*fsv1.EvaluateResponse, error) {
+	msg := &fsv1.EvaluateResponse{
+		Variant: string(d.Value),
+		Reason:  reasons[d.Reason],
+	}
+	if err := setTypedValue(msg, d); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// resultProto converts a batch result to its wire format.
+func resultProto(r flagsheet.EvaluationResult, entityID string) *fsv1.EvaluationResult {
+	msg := &fsv1.EvaluationResult{
+		Feature:  r.Key,
+		EntityId: entityID,
+	}
+	if r.Err != nil {
+		msg.Error = r.Err.Error()
+		msg.MissingKey = missingKey(r.Err)
+		return msg
+	}
+	res, err := evaluateResponse(r.EvaluationDetail)
+	if err != nil {
+		msg.Error = err.Error()
+		return msg
+	}
+	msg.Response = res
+	return msg
+}
+
+func (s *FlagSheetServer) Explain(
+	ctx context.Context,
+	req *connect.Request[fsv1.ExplainRequest],
+) (*connect.Response[fsv1.ExplainResponse], error) {
+	id, err := requestEntity(req.Msg.EntityId, req.Msg.Anonymous)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.fs.Explain(req.Msg.Feature, flagsheet.EvaluationContext{
+		ID:         id,
+		Attributes: attributes(req.Msg.Attributes),
+	})
+	if err != nil {
+		return nil, connectError(err)
+	}
+	res := connect.NewResponse(&fsv1.ExplainResponse{
+		Variant:         string(d.Value),
+		Reason:          reasons[d.Reason],
+		Layer:           d.Layer,
+		LayerVersion:    int64(d.LayerVersion),
+		Bucket:          int64(d.Bucket),
+		Rule:            d.Rule,
+		SnapshotVersion: d.SnapshotVersion,
+	})
+	res.Header().Set(flagSheetVersionKey, flagSheetVersionValue)
+	return res, nil
+}
+
+func (s *FlagSheetServer) ListFeatures(
+	ctx context.Context,
+	req *connect.Request[fsv1.ListFeaturesRequest],
+) (*connect.Response[fsv1.ListFeaturesResponse], error) {
+	features := s.fs.Features()
+	msg := &fsv1.ListFeaturesResponse{
+		Features: make([]*fsv1.Feature, len(features)),
+	}
+	for i, feature := range features {
+		msg.Features[i] = featureProto(feature)
+	}
+	res := connect.NewResponse(msg)
+	res.Header().Set(flagSheetVersionKey, flagSheetVersionValue)
+	return res, nil
+}
+
+func (s *FlagSheetServer) GetFeature(
+	ctx context.Context,
+	req *connect.Request[fsv1.GetFeatureRequest],
+) (*connect.Response[fsv1.GetFeatureResponse], error) {
+	feature, ok := s.fs.Feature(req.Msg.Feature)
+	if !ok {
+		return nil, connectError(&flagsheet.KeyError{Key: req.Msg.Feature, Err: flagsheet.ErrFeatureNotFound})
+	}
+	res := connect.NewResponse(&fsv1.GetFeatureResponse{Feature: featureProto(feature)})
+	res.Header().Set(flagSheetVersionKey, flagSheetVersionValue)
+	return res, nil
+}
+
+func (s *FlagSheetServer) ListLayers(
+	ctx context.Context,
+	req *connect.Request[fsv1.ListLayersRequest],
+) (*connect.Response[fsv1.ListLayersResponse], error) {
+	layers := s.fs.Layers()
+	msg := &fsv1.ListLayersResponse{
+		Layers: make([]*fsv1.Layer, len(layers)),
+	}
+	for i, layer := range layers {
+		msg.Layers[i] = &fsv1.Layer{
+			Name:    layer.Name,
+			Version: int64(layer.Version),
+			Legacy:  layer.Legacy,
+			Size:    int64(layer.Size()),
+			Filled:  int64(layer.Filled()),
+		}
+	}
+	res := connect.NewResponse(msg)
+	res.Header().Set(flagSheetVersionKey, flagSheetVersionValue)
+	return res, nil
+}
+
+// featureProto converts a feature to its wire format.
+func featureProto(feature flagsheet.Feature) *fsv1.Feature {
+	msg := &fsv1.Feature{
+		Key:      feature.Key,
+		Layer:    feature.LayerName,
+		Type:     string(feature.Type),
+		Default:  string(feature.Default),
+		Variants: variantsProto(feature.VariantMap),
+		Filled:   int64(feature.Filled()),
+	}
+	for _, target := range feature.Targets {
+		msg.Targets = append(msg.Targets, &fsv1.Target{
+			Rule:     target.Rule,
+			Variants: variantsProto(target.VariantMap),
+			Filled:   int64(target.Filled()),
+		})
+	}
+	return msg
+}
+
+// variantsProto converts a variant map to its wire format, sorted by value.
+func variantsProto(variants map[string]flagsheet.FeatureVariant) []*fsv1.Variant {
+	msgs := make([]*fsv1.Variant, 0, len(variants))
+	for _, variant := range variants {
+		msgs = append(msgs, &fsv1.Variant{
+			Value:  string(variant.Value),
+			Weight: int64(variant.Percentage),
+		})
+	}
+	sort.Slice(msgs, func(i, j int) bool {
+		return msgs[i].Value < msgs[j].Value
+	})
+	return msgs
+}
+
+func (s *FlagSheetServer) GetConfig(
+	ctx context.Context,
+	req *connect.Request[fsv1.GetConfigRequest],
+) (*connect.Response[fsv1.GetConfigResponse], error) {
+	tables, version := s.fs.Tables()
+	if tables == nil {
+		return nil, connectError(flagsheet.ErrNotReady)
+	}
+	res := connect.NewResponse(&fsv1.GetConfigResponse{Config: configProto(tables, version)})
+	res.Header().Set(flagSheetVersionKey, flagSheetVersionValue)
+	return res, nil
+}
+
+func (s *FlagSheetServer) Watch(
+	ctx context.Context,
+	req *connect.Request[fsv1.WatchRequest],
+	stream *connect.ServerStream[fsv1.WatchResponse],
+) error {
+	changed := make(chan struct{}, 1)
+	unsubscribe := s.fs.Subscribe(func(flagsheet.ChangeEvent) {
+		select {
+		case changed <- struct{}{}:
+		default:
+			// a send is already pending, and will pick up the latest config
+		}
+	})
+	defer unsubscribe()
+	stream.ResponseHeader().Set(flagSheetVersionKey, flagSheetVersionValue)
+
+	var sent string
+	for {
+		if tables, version := s.fs.Tables(); version != sent {
+			if err := stream.Send(&fsv1.WatchResponse{Config: configProto(tables, version)}); err != nil {
+				return err
+			}
+			sent = version
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil
+		case <-s.done:
+			return nil
+		}
+	}
+}
+
+// configProto converts tables to their wire format.
+func configProto(tables *flagsheet.Tables, version string) *fsv1.Config {
+	rows := func(table [][]string) []*fsv1.Row {
+		msgs := make([]*fsv1.Row, len(table))
+		for i, row := range table {
+			msgs[i] = &fsv1.Row{Cells: row}
+		}
+		return msgs
+	}
+	return &fsv1.Config{
+		Version:   version,
+		Flags:     rows(tables.Flags),
+		Layers:    rows(tables.Layers),
+		Overrides: rows(tables.Overrides),
+	}
+}
+
+// setTypedValue sets the response value to the variant converted to its type.
+func setTypedValue(msg *fsv1.EvaluateResponse, d flagsheet.EvaluationDetail) error {
+	v, err := d.Typed()
+	if err != nil || v == nil {
+		return err
+	}
+	switch d.Type {
+	case flagsheet.TypeBool:
+		msg.Value = &fsv1.EvaluateResponse_BoolValue{BoolValue: v.(bool)}
+	case flagsheet.TypeInt:
+		msg.Value = &fsv1.EvaluateResponse_IntValue{IntValue: v.(int64)}
+	case flagsheet.TypeFloat:
+		msg.Value = &fsv1.EvaluateResponse_FloatValue{FloatValue: v.(float64)}
+	case flagsheet.TypeJSON:
+		jv, err := structpb.NewValue(v)
+		if err != nil {
+			return err
+		}
+		msg.Value = &fsv1.EvaluateResponse_JsonValue{JsonValue: jv}
+	default:
+		msg.Value = &fsv1.EvaluateResponse_StringValue{StringValue: v.(string)}
+	}
+	return nil
+}
+
+// requestEntity returns the id to evaluate for, which is nil for anonymous requests.
+func requestEntity(id string, anonymous bool) (*string, error) {
+	if !anonymous {
+		return &id, nil
+	}
+	if id != "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("anonymous requests must not have an entity id"))
+	}
+	return nil, nil
+}
+
+// attributes converts request attributes to plain Go values for rule matching.
+func attributes(values map[string]*structpb.Value) map[string]interface{} {
+	attrs := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		attrs[k] = v.AsInterface()
+	}
+	return attrs
+}
+
+// healthChecker reports the service as not serving once the flags are
+// older than the configured max staleness.
+type healthChecker struct {
+	*grpchealth.StaticChecker
+	fs *flagsheet.FlagSheet
+}
+
+func (c *healthChecker) Check(ctx context.Context, req *grpchealth.CheckRequest) (*grpchealth.CheckResponse, error) {
+	res, err := c.StaticChecker.Check(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if !c.fs.Healthy() {
+		res.Status = grpchealth.StatusNotServing
+	}
+	return res, nil
+}
+
+func (c *healthChecker) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	if !c.fs.Healthy() {
+		http.Error(w, "flags are stale", http.StatusServiceUnavailable)
+	}
+}
+
+// setupTracing exports traces with OTLP over HTTP if an OTLP endpoint is set
+// with the standard OTEL_EXPORTER_OTLP_ENDPOINT env vars, and returns a
+// function that flushes them.
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %v", err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// sourceFromEnv reads flags from FLAGSHEET_PATH if it is set,
+// and from the SPREADSHEET_ID Google sheet otherwise. If
+// FLAGSHEET_DRIVE_REVISIONS is true, the sheet is only fetched when its Drive
+// version changes, which needs the Drive API enabled in the GCP project.
+func sourceFromEnv() (flagsheet.Source, error) {
+	if path := os.Getenv("FLAGSHEET_PATH"); path != "" {
+		return flagsheet.NewFileSource(path), nil
+	}
+
+	var driveRevisions bool
+	if v := os.Getenv("FLAGSHEET_DRIVE_REVISIONS"); v != "" {
+		var err error
+		driveRevisions, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FLAGSHEET_DRIVE_REVISIONS: %v", err)
+		}
+	}
+	scopes := []string{spreadsheet.Scope}
+	if driveRevisions {
+		scopes = append(scopes, flagsheet.DriveScope)
+	}
+	client, err := flagsheet.NewGoogleClientFromEnv(context.Background(), scopes...)
+	if err != nil {
+		return nil, err
 	}
 
 	spreadsheetID := os.Getenv("SPREADSHEET_ID")
 	if spreadsheetID == "" {
-		panic("SPREADSHEET_ID env var must be set")
+		return nil, errors.New("SPREADSHEET_ID env var must be set")
+	}
+	service := spreadsheet.NewServiceWithClient(client)
+	source := flagsheet.NewSheetsSource(service, spreadsheetID)
+	if driveRevisions {
+		source = source.WithDriveClient(client)
+	}
+	return source, nil
+}
+
+// exposuresFromEnv logs exposures to FLAGSHEET_EXPOSURE_LOG, which is
+// "stdout", an http(s) webhook URL or a file path, deduplicating them within
+// FLAGSHEET_EXPOSURE_DEDUPE_WINDOW. It returns a nil logger if the env var
+// is not set, and a function that flushes the logged exposures.
+func exposuresFromEnv() (flagsheet.ExposureLogger, func(context.Context) error, error) {
+	target := os.Getenv("FLAGSHEET_EXPOSURE_LOG")
+	if target == "" {
+		return nil, func(context.Context) error { return nil }, nil
+	}
+	var cfg flagsheet.ExposureBatcherConfig
+	if v := os.Getenv("FLAGSHEET_EXPOSURE_DEDUPE_WINDOW"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid FLAGSHEET_EXPOSURE_DEDUPE_WINDOW: %v", err)
+		}
+		cfg.DedupeWindow = d
+	}
+	var sink flagsheet.ExposureSink
+	closeSink := func() error { return nil }
+	switch {
+	case target == "stdout":
+		sink = flagsheet.NewStdoutExposureSink()
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		sink = flagsheet.NewWebhookSink(target).WithClient(&http.Client{Timeout: 10 * time.Second})
+	default:
+		file, err := flagsheet.NewFileExposureSink(target)
+		if err != nil {
+			return nil, nil, err
+		}
+		sink, closeSink = file, file.Close
+	}
+	batcher := flagsheet.NewExposureBatcher(sink, cfg)
+	return batcher, func(ctx context.Context) error {
+		return errors.Join(batcher.Close(ctx), closeSink())
+	}, nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := setupTracing(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	source, err := sourceFromEnv()
+	if err != nil {
+		panic(err)
 	}
 
-	fs, err := flagsheet.NewFlagSheet(service, spreadsheetID, 10*time.Second)
+	exposures, closeExposures, err := exposuresFromEnv()
 	if err != nil {
 		panic(err)
 	}
 
+	opts := []flagsheet.Option{
+		flagsheet.WithMetrics(metrics.NewPrometheus(prometheus.DefaultRegisterer)),
+	}
+	if exposures != nil {
+		opts = append(opts, flagsheet.WithExposureLogger(exposures))
+	}
+	if path := os.Getenv("FLAGSHEET_SNAPSHOT_PATH"); path != "" {
+		opts = append(opts, flagsheet.WithSnapshotFile(path))
+	}
+	if v := os.Getenv("FLAGSHEET_MAX_STALENESS"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			panic(fmt.Errorf("invalid FLAGSHEET_MAX_STALENESS: %v", err))
+		}
+		opts = append(opts, flagsheet.WithMaxStaleness(d))
+	}
+	fs, err := flagsheet.NewFlagSheet(ctx, source, 10*time.Second, opts...)
+	if err != nil {
+		panic(err)
+	}
+	if fs.Stale() {
+		log.Printf("WARNING: serving stale flags from %s until the source recovers", os.Getenv("FLAGSHEET_SNAPSHOT_PATH"))
+	}
+
 	// serving
 	s := &FlagSheetServer{
-		fs: fs,
+		fs:   fs,
+		done: ctx.Done(),
 	}
 	mux := http.NewServeMux()
-	path, handler := flagsheetv1connect.NewFlagSheetServiceHandler(s)
-	mux.Handle(path, handler)
-	checker := grpchealth.NewStaticChecker(
-		"flagsheet.v1.FlagSheetService",
+	path, handler := flagsheetv1connect.NewFlagSheetServiceHandler(
+		s,
+		connect.WithInterceptors(otelconnect.NewInterceptor()),
 	)
+	mux.Handle(path, handler)
+	mux.Handle(ofrep.Path, ofrep.NewHandler(fs))
+	checker := &healthChecker{
+		StaticChecker: grpchealth.NewStaticChecker(
+			"flagsheet.v1.FlagSheetService",
+		),
+		fs: fs,
+	}
 	mux.Handle(grpchealth.NewHandler(checker))
-	mux.Handle("/health", http.HandlerFunc(ok))
+	mux.Handle("/health", checker)
+	mux.Handle("/metrics", promhttp.Handler())
 	portNum := os.Getenv("PORT")
 	if portNum == "" {
 		portNum = "8080"
 	}
-	http.ListenAndServe(
-		":"+portNum,
-		h2c.NewHandler(mux, &http2.Server{}),
-	)
+	srv := &http.Server{
+		Addr:    ":" + portNum,
+		Handler: h2c.NewHandler(mux, &http2.Server{}),
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to serve: %v", err)
+		}
+	}()
+
+	// shut down gracefully on SIGINT or SIGTERM
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to shut down server: %v", err)
+	}
+	if err := fs.Close(shutdownCtx); err != nil {
+		log.Printf("failed to stop refreshing flags: %v", err)
+	}
+	if err := closeExposures(shutdownCtx); err != nil {
+		log.Printf("failed to flush exposures: %v", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("failed to flush traces: %v", err)
+	}
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stillmatic/flagsheet"
+	fsv1 "github.com/stillmatic/flagsheet/gen/flagsheet/v1"
+	"github.com/stillmatic/flagsheet/gen/flagsheet/v1/flagsheetv1connect"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTables() *flagsheet.Tables {
+	return &flagsheet.Tables{
+		Flags: [][]string{
+			{"Key", "Layer", "Value", "Weight"},
+			{"my_key", "a", "foo", "250"},
+			{"my_key", "a", "bar", "750"},
+		},
+		Layers: [][]string{
+			{"Layer", "Version"},
+			{"a", "1"},
+		},
+	}
+}
+
+// newTestServer serves a FlagSheetServer over the source, and returns a client for it.
+func newTestServer(t *testing.T, source flagsheet.Source) (flagsheetv1connect.FlagSheetServiceClient, *flagsheet.FlagSheet) {
+	t.Helper()
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0)
+	assert.NoError(t, err)
+	done := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.Handle(flagsheetv1connect.NewFlagSheetServiceHandler(&FlagSheetServer{fs: fs, done: done}))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		close(done)
+		ts.Close()
+	})
+	return flagsheetv1connect.NewFlagSheetServiceClient(ts.Client(), ts.URL), fs
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	client, _ := newTestServer(t, flagsheet.NewStaticSource(testTables()))
+	ctx := context.Background()
+
+	res, err := client.Evaluate(ctx, connect.NewRequest(&fsv1.EvaluateRequest{Feature: "my_key", EntityId: "my_id"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", res.Msg.Variant)
+
+	_, err = client.Evaluate(ctx, connect.NewRequest(&fsv1.EvaluateRequest{Feature: "missing", EntityId: "my_id"}))
+	assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	var cerr *connect.Error
+	if assert.True(t, errors.As(err, &cerr)) && assert.Len(t, cerr.Details(), 1) {
+		msg, err := cerr.Details()[0].Value()
+		assert.NoError(t, err)
+		mk, ok := msg.(*fsv1.MissingKey)
+		if assert.True(t, ok) {
+			assert.Equal(t, fsv1.MissingKey_KIND_FEATURE, mk.Kind)
+			assert.Equal(t, "missing", mk.Key)
+		}
+	}
+
+	_, err = client.Explain(ctx, connect.NewRequest(&fsv1.ExplainRequest{Feature: "missing"}))
+	assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+}
+
+func TestConnectError(t *testing.T) {
+	cases := []struct {
+		err  error
+		code connect.Code
+		kind fsv1.MissingKey_Kind
+	}{
+		{flagsheet.ErrNotReady, connect.CodeUnavailable, fsv1.MissingKey_KIND_UNSPECIFIED},
+		{&flagsheet.KeyError{Key: "f", Err: flagsheet.ErrFeatureNotFound}, connect.CodeNotFound, fsv1.MissingKey_KIND_FEATURE},
+		{&flagsheet.KeyError{Key: "l", Err: flagsheet.ErrLayerNotFound}, connect.CodeNotFound, fsv1.MissingKey_KIND_LAYER},
+		{errors.New("boom"), connect.CodeInternal, fsv1.MissingKey_KIND_UNSPECIFIED},
+	}
+	for _, c := range cases {
+		cerr := connectError(c.err)
+		assert.Equal(t, c.code, cerr.Code(), c.err.Error())
+		assert.Equal(t, c.kind, missingKey(c.err).GetKind(), c.err.Error())
+		assert.ErrorIs(t, cerr, c.err)
+	}
+}
+
+func TestBatchEvaluate(t *testing.T) {
+	client, _ := newTestServer(t, flagsheet.NewStaticSource(testTables()))
+	res, err := client.BatchEvaluate(context.Background(), connect.NewRequest(&fsv1.BatchEvaluateRequest{
+		Requests: []*fsv1.EvaluateRequest{{Feature: "my_key", EntityId: "my_id"}, {Feature: "missing"}},
+	}))
+	assert.NoError(t, err)
+	// failed evaluations don't fail the batch
+	if assert.Len(t, res.Msg.Results, 2) {
+		assert.Equal(t, "bar", res.Msg.Results[0].Response.GetVariant())
+		assert.Nil(t, res.Msg.Results[1].Response)
+		assert.NotEmpty(t, res.Msg.Results[1].Error)
+		assert.Equal(t, fsv1.MissingKey_KIND_FEATURE, res.Msg.Results[1].MissingKey.GetKind())
+		assert.Equal(t, "missing", res.Msg.Results[1].MissingKey.GetKey())
+	}
+}
+
+func TestAnonymousRequests(t *testing.T) {
+	client, _ := newTestServer(t, flagsheet.NewStaticSource(testTables()))
+	ctx := context.Background()
+
+	res, err := client.Explain(ctx, connect.NewRequest(&fsv1.ExplainRequest{Feature: "my_key", Anonymous: true}))
+	assert.NoError(t, err)
+	assert.Contains(t, []string{"foo", "bar"}, res.Msg.Variant)
+
+	_, err = client.Evaluate(ctx, connect.NewRequest(&fsv1.EvaluateRequest{Feature: "my_key", EntityId: "my_id", Anonymous: true}))
+	assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	_, err = client.EvaluateAll(ctx, connect.NewRequest(&fsv1.EvaluateAllRequest{EntityId: "my_id", Anonymous: true}))
+	assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	_, err = client.BatchEvaluate(ctx, connect.NewRequest(&fsv1.BatchEvaluateRequest{
+		Requests: []*fsv1.EvaluateRequest{{Feature: "my_key", EntityId: "my_id", Anonymous: true}},
+	}))
+	assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+}
+
+func TestWatch(t *testing.T) {
+	source := flagsheet.NewStaticSource(testTables())
+	client, fs := newTestServer(t, source)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, connect.NewRequest(&fsv1.WatchRequest{}))
+	assert.NoError(t, err)
+	defer func() {
+		// the stream only ends once canceled
+		cancel()
+		stream.Close()
+	}()
+	// the current config is sent first
+	assert.True(t, stream.Receive())
+	_, version := fs.Tables()
+	first := stream.Msg().Config
+	assert.Equal(t, version, first.Version)
+	assert.Equal(t, []string{"my_key", "a", "foo", "250"}, first.Flags[1].Cells)
+
+	// then every change
+	tables := testTables()
+	tables.Flags[2][3] = "500"
+	source.Set(tables)
+	assert.NoError(t, fs.Refresh())
+	assert.True(t, stream.Receive())
+	assert.NotEqual(t, first.Version, stream.Msg().Config.Version)
+	assert.Equal(t, "500", stream.Msg().Config.Flags[2].Cells[3])
+}
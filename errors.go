@@ -0,0 +1,38 @@
+package flagsheet
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrFeatureNotFound means the feature is not in the loaded configuration.
+	ErrFeatureNotFound = errors.New("feature not found")
+	// ErrLayerNotFound means the layer is not in the loaded configuration.
+	ErrLayerNotFound = errors.New("layer not found")
+	// ErrNotReady means no configuration is loaded yet, or the server could
+	// not be reached.
+	ErrNotReady = errors.New("flag sheet is not loaded")
+)
+
+// KeyError identifies the feature or layer that was not found.
+// It wraps ErrFeatureNotFound or ErrLayerNotFound, so check for those with errors.Is,
+// and use errors.As to get the key.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	if errors.Is(e.Err, ErrLayerNotFound) {
+		return fmt.Sprintf("layer %s not found", e.Key)
+	}
+	if errors.Is(e.Err, ErrFeatureNotFound) {
+		return fmt.Sprintf("feature %s not found", e.Key)
+	}
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,289 @@
+package flagsheet
+
+import (
+	"context"
+	"hash/maphash"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Exposure records that an entity was assigned a variant of a feature, for
+// experiment analysis.
+type Exposure struct {
+	EntityID     string       `json:"entity_id"`
+	Feature      string       `json:"feature"`
+	Variant      FeatureValue `json:"variant"`
+	Reason       Reason       `json:"reason"`
+	Rule         string       `json:"rule,omitempty"`
+	Layer        string       `json:"layer"`
+	LayerVersion int          `json:"layer_version"`
+	Timestamp    time.Time    `json:"timestamp"`
+}
+
+// ExposureLogger receives the assignments made by a FlagSheet, see
+// WithExposureLogger. Log is called on every such evaluation, so it must be
+// safe for concurrent use and must not block; ExposureBatcher buffers
+// exposures and writes them to an ExposureSink in the background.
+type ExposureLogger interface {
+	Log(e Exposure)
+}
+
+// ExposureSink writes batches of exposures, e.g. to a file or a webhook.
+// It is called from a single goroutine and must not retain the slice.
+type ExposureSink interface {
+	WriteExposures(ctx context.Context, exposures []Exposure) error
+}
+
+// logExposure logs bucketed assignments of identified entities. Overrides,
+// defaults and random buckets are not part of an experiment, so they are
+// not logged.
+func (f *flagSheet) logExposure(d EvaluationDetail, ectx EvaluationContext, at time.Time) {
+	if ectx.ID == nil || d.Reason != ReasonBucket && d.Reason != ReasonTargetingMatch {
+		return
+	}
+	f.exposures.Log(Exposure{
+		EntityID:     *ectx.ID,
+		Feature:      d.Key,
+		Variant:      d.Value,
+		Reason:       d.Reason,
+		Rule:         d.Rule,
+		Layer:        d.Layer,
+		LayerVersion: d.LayerVersion,
+		Timestamp:    at,
+	})
+}
+
+// ExposureBatcherConfig configures an ExposureBatcher.
+// Zero values use the defaults.
+type ExposureBatcherConfig struct {
+	// BatchSize is the most exposures written at once. It defaults to 500.
+	BatchSize int
+	// FlushInterval is how often a partial batch is written. It defaults to 5 seconds.
+	FlushInterval time.Duration
+	// BufferSize is how many exposures can wait to be written. Once it is
+	// full, e.g. because the sink is slow, exposures are dropped rather than
+	// slowing down evaluation. It defaults to 10000.
+	BufferSize int
+	// DedupeWindow drops exposures of an entity to a feature that was
+	// already logged within the window with the same variant and layer
+	// version. Zero disables deduplication.
+	DedupeWindow time.Duration
+}
+
+const (
+	defaultExposureBatchSize     = 500
+	defaultExposureFlushInterval = 5 * time.Second
+	defaultExposureBufferSize    = 10000
+)
+
+// ExposureBatcher is an ExposureLogger that deduplicates exposures and writes
+// them to a sink in batches from a background goroutine, until Close.
+type ExposureBatcher struct {
+	sink    ExposureSink
+	cfg     ExposureBatcherConfig
+	queue   chan Exposure
+	dropped atomic.Uint64
+
+	// seen is split into shards by entity and feature, see seenShards.
+	seed maphash.Seed
+	seen [seenShards]seenShard
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	stop      chan struct{}
+	// done is closed once the last batch is written.
+	done chan struct{}
+}
+
+// seenShards is how many locks the dedupe map is split over, so that
+// concurrent evaluations rarely contend, and pruning only holds up the
+// evaluations of one shard at a time.
+const seenShards = 64
+
+// seenShard tracks when each entity and feature was last logged.
+type seenShard struct {
+	mu   sync.Mutex
+	seen map[exposureKey]seenExposure
+}
+
+type exposureKey struct {
+	entityID string
+	feature  string
+}
+
+type seenExposure struct {
+	variant      FeatureValue
+	layerVersion int
+	at           time.Time
+}
+
+// NewExposureBatcher starts writing exposures to sink.
+func NewExposureBatcher(sink ExposureSink, cfg ExposureBatcherConfig) *ExposureBatcher {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultExposureBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultExposureFlushInterval
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultExposureBufferSize
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &ExposureBatcher{
+		sink:   sink,
+		cfg:    cfg,
+		queue:  make(chan Exposure, cfg.BufferSize),
+		seed:   maphash.MakeSeed(),
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for i := range b.seen {
+		b.seen[i].seen = make(map[exposureKey]seenExposure)
+	}
+	go b.run()
+	return b
+}
+
+// Log queues an exposure to be written, unless it is a duplicate or the
+// buffer is full. It never blocks. Exposures logged after Close are dropped.
+func (b *ExposureBatcher) Log(e Exposure) {
+	select {
+	case <-b.stop:
+		b.dropped.Add(1)
+		return
+	default:
+	}
+	if b.cfg.DedupeWindow <= 0 {
+		b.enqueue(e)
+		return
+	}
+	key := exposureKey{entityID: e.EntityID, feature: e.Feature}
+	shard := b.shard(key)
+	entry := seenExposure{variant: e.Variant, layerVersion: e.LayerVersion, at: e.Timestamp}
+	shard.mu.Lock()
+	last, ok := shard.seen[key]
+	if ok && last.variant == e.Variant && last.layerVersion == e.LayerVersion &&
+		e.Timestamp.Sub(last.at) < b.cfg.DedupeWindow {
+		shard.mu.Unlock()
+		return
+	}
+	// mark it as seen before queueing it, so that concurrent duplicates are skipped
+	shard.seen[key] = entry
+	shard.mu.Unlock()
+	if b.enqueue(e) {
+		return
+	}
+	// it was dropped, so the next evaluation should log it again
+	shard.mu.Lock()
+	if shard.seen[key] == entry {
+		if ok {
+			shard.seen[key] = last
+		} else {
+			delete(shard.seen, key)
+		}
+	}
+	shard.mu.Unlock()
+}
+
+// enqueue queues an exposure unless the buffer is full, and reports whether it did.
+func (b *ExposureBatcher) enqueue(e Exposure) bool {
+	select {
+	case b.queue <- e:
+		return true
+	default:
+		b.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped returns how many exposures were dropped because the buffer was full
+// or the batcher was closed.
+func (b *ExposureBatcher) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+func (b *ExposureBatcher) shard(key exposureKey) *seenShard {
+	var h maphash.Hash
+	h.SetSeed(b.seed)
+	h.WriteString(key.entityID)
+	h.WriteByte(0)
+	h.WriteString(key.feature)
+	return &b.seen[h.Sum64()%seenShards]
+}
+
+// prune forgets exposures older than the dedupe window, so that seen only
+// holds recently active entities. It locks one shard at a time.
+func (b *ExposureBatcher) prune(now time.Time) {
+	if b.cfg.DedupeWindow <= 0 {
+		return
+	}
+	for i := range b.seen {
+		shard := &b.seen[i]
+		shard.mu.Lock()
+		for key, last := range shard.seen {
+			if now.Sub(last.at) >= b.cfg.DedupeWindow {
+				delete(shard.seen, key)
+			}
+		}
+		shard.mu.Unlock()
+	}
+}
+
+func (b *ExposureBatcher) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.cfg.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]Exposure, 0, b.cfg.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := b.sink.WriteExposures(b.ctx, batch); err != nil {
+			log.Printf("failed to write %d exposures: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case e := <-b.queue:
+			batch = append(batch, e)
+			if len(batch) >= b.cfg.BatchSize {
+				flush()
+			}
+		case now := <-ticker.C:
+			flush()
+			b.prune(now)
+		case <-b.stop:
+			for {
+				select {
+				case e := <-b.queue:
+					batch = append(batch, e)
+					if len(batch) >= b.cfg.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// Close writes the queued exposures and stops the batcher. If ctx is done
+// first, in-flight writes are canceled. It is safe to call Close more than once.
+func (b *ExposureBatcher) Close(ctx context.Context) error {
+	b.closeOnce.Do(func() { close(b.stop) })
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
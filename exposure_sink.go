@@ -0,0 +1,102 @@
+package flagsheet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// JSONLinesSink writes exposures to a writer as JSON, one per line.
+type JSONLinesSink struct {
+	mu sync.Mutex
+	w  io.Writer
+	// closer closes the file opened by NewFileExposureSink.
+	closer io.Closer
+}
+
+// NewJSONLinesSink writes exposures to w.
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{w: w}
+}
+
+// NewStdoutExposureSink writes exposures to stdout, e.g. for a log collector.
+func NewStdoutExposureSink() *JSONLinesSink {
+	return NewJSONLinesSink(os.Stdout)
+}
+
+// NewFileExposureSink appends exposures to the file at path, creating it if needed.
+func NewFileExposureSink(path string) (*JSONLinesSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open exposure log: %w", err)
+	}
+	return &JSONLinesSink{w: f, closer: f}, nil
+}
+
+func (s *JSONLinesSink) WriteExposures(_ context.Context, exposures []Exposure) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// buffer the batch so that it is written at once
+	w := bufio.NewWriter(s.w)
+	enc := json.NewEncoder(w)
+	for _, e := range exposures {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Close closes the file of a sink created by NewFileExposureSink, and does
+// nothing otherwise.
+func (s *JSONLinesSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// WebhookSink POSTs each batch of exposures to a URL as a JSON array.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink posts exposures to url with http.DefaultClient.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{url: url, client: http.DefaultClient}
+}
+
+// WithClient posts with client, e.g. to set a timeout or authentication.
+func (s *WebhookSink) WithClient(client *http.Client) *WebhookSink {
+	s.client = client
+	return s
+}
+
+func (s *WebhookSink) WriteExposures(ctx context.Context, exposures []Exposure) error {
+	body, err := json.Marshal(exposures)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post exposures: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("failed to post exposures: %s", res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package flagsheet_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stillmatic/flagsheet"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	mu        sync.Mutex
+	exposures []flagsheet.Exposure
+}
+
+func (l *recordingLogger) Log(e flagsheet.Exposure) {
+	l.mu.Lock()
+	l.exposures = append(l.exposures, e)
+	l.mu.Unlock()
+}
+
+// recordingSink records batches, optionally signaling entered and blocking
+// until release is closed.
+type recordingSink struct {
+	mu      sync.Mutex
+	batches [][]flagsheet.Exposure
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *recordingSink) WriteExposures(ctx context.Context, exposures []flagsheet.Exposure) error {
+	if s.entered != nil {
+		select {
+		case s.entered <- struct{}{}:
+		default:
+		}
+	}
+	if s.release != nil {
+		select {
+		case <-s.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	s.mu.Lock()
+	s.batches = append(s.batches, append([]flagsheet.Exposure(nil), exposures...))
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *recordingSink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, batch := range s.batches {
+		n += len(batch)
+	}
+	return n
+}
+
+func TestExposureLogging(t *testing.T) {
+	tables := exampleTables()
+	tables.Overrides = [][]string{
+		{"Key", "EntityID", "Variant"},
+		{"my_key", "qa_user", "foo"},
+	}
+	logger := &recordingLogger{}
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0,
+		flagsheet.WithExposureLogger(logger))
+	assert.NoError(t, err)
+
+	d, err := fs.EvaluateDetail("my_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")})
+	assert.NoError(t, err)
+	assert.Len(t, logger.exposures, 1)
+	e := logger.exposures[0]
+	assert.Equal(t, "my_id", e.EntityID)
+	assert.Equal(t, "my_key", e.Feature)
+	assert.Equal(t, d.Value, e.Variant)
+	assert.Equal(t, flagsheet.ReasonBucket, e.Reason)
+	assert.Equal(t, "a", e.Layer)
+	assert.Equal(t, 1, e.LayerVersion)
+	assert.False(t, e.Timestamp.IsZero())
+
+	// random buckets, overrides and misses are not exposures
+	_, _ = fs.Evaluate("my_key", nil)
+	_, _ = fs.Evaluate("my_key", stringPtr("qa_user"))
+	_, _ = fs.Evaluate("missing", stringPtr("my_id"))
+	assert.Len(t, logger.exposures, 1)
+	// and neither is explaining an evaluation
+	_, err = fs.Explain("my_key", flagsheet.EvaluationContext{ID: stringPtr("other_id")})
+	assert.NoError(t, err)
+	assert.Len(t, logger.exposures, 1)
+
+	// batches and EvaluateAll log too
+	fs.BatchEvaluate([]flagsheet.EvaluationRequest{{Key: "my_key", Context: flagsheet.EvaluationContext{ID: stringPtr("other_id")}}})
+	assert.Len(t, logger.exposures, 2)
+	results, err := fs.EvaluateAll(flagsheet.EvaluationContext{ID: stringPtr("my_id")})
+	assert.NoError(t, err)
+	bucketed := 0
+	for _, r := range results {
+		if r.Reason == flagsheet.ReasonBucket {
+			bucketed++
+		}
+	}
+	assert.Len(t, logger.exposures, 2+bucketed)
+}
+
+func TestExposureBatcher(t *testing.T) {
+	sink := &recordingSink{}
+	b := flagsheet.NewExposureBatcher(sink, flagsheet.ExposureBatcherConfig{
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		DedupeWindow:  time.Minute,
+	})
+	now := time.Now()
+	exposure := func(id string, variant string, at time.Time) flagsheet.Exposure {
+		return flagsheet.Exposure{EntityID: id, Feature: "my_key", Variant: flagsheet.FeatureValue(variant), LayerVersion: 1, Timestamp: at}
+	}
+	b.Log(exposure("a", "foo", now))
+	b.Log(exposure("a", "foo", now.Add(time.Second)))   // duplicate
+	b.Log(exposure("a", "bar", now.Add(2*time.Second))) // new variant
+	b.Log(exposure("b", "foo", now))
+	b.Log(exposure("a", "bar", now.Add(2*time.Minute))) // outside the window
+	// full batches are written without waiting for the flush interval
+	assert.Eventually(t, func() bool { return sink.count() == 4 }, time.Second, time.Millisecond)
+	// Close writes the partial batch
+	b.Log(exposure("c", "foo", now))
+	assert.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, 5, sink.count())
+	assert.Len(t, sink.batches, 3)
+	assert.NoError(t, b.Close(context.Background()))
+
+	b.Log(exposure("d", "foo", now))
+	assert.Equal(t, uint64(1), b.Dropped())
+	assert.Equal(t, 5, sink.count())
+}
+
+func TestExposureBatcherBackpressure(t *testing.T) {
+	sink := &recordingSink{release: make(chan struct{})}
+	b := flagsheet.NewExposureBatcher(sink, flagsheet.ExposureBatcherConfig{
+		BatchSize:  1,
+		BufferSize: 2,
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			b.Log(flagsheet.Exposure{EntityID: fmt.Sprint(i), Feature: "my_key"})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Log blocked on a slow sink")
+	}
+	assert.GreaterOrEqual(t, b.Dropped(), uint64(97))
+	close(sink.release)
+	assert.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, uint64(100), b.Dropped()+uint64(sink.count()))
+}
+
+func TestExposureBatcherRetryAfterDrop(t *testing.T) {
+	sink := &recordingSink{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	b := flagsheet.NewExposureBatcher(sink, flagsheet.ExposureBatcherConfig{
+		BatchSize:    1,
+		BufferSize:   1,
+		DedupeWindow: time.Hour,
+	})
+	now := time.Now()
+	exposure := func(id string) flagsheet.Exposure {
+		return flagsheet.Exposure{EntityID: id, Feature: "my_key", Variant: "foo", Timestamp: now}
+	}
+	b.Log(exposure("a"))
+	<-sink.entered // a is being written, and the sink is stuck
+	b.Log(exposure("b"))
+	b.Log(exposure("c")) // the buffer is full
+	assert.Equal(t, uint64(1), b.Dropped())
+	close(sink.release)
+	assert.Eventually(t, func() bool { return sink.count() == 2 }, time.Second, time.Millisecond)
+	// the dropped exposure isn't treated as a duplicate
+	b.Log(exposure("c"))
+	b.Log(exposure("c"))
+	assert.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, 3, sink.count())
+	assert.Equal(t, "c", sink.batches[2][0].EntityID)
+}
+
+func TestExposureSinks(t *testing.T) {
+	exposures := []flagsheet.Exposure{
+		{EntityID: "a", Feature: "my_key", Variant: "foo", Layer: "a", LayerVersion: 1},
+		{EntityID: "b", Feature: "my_key", Variant: "bar", Layer: "a", LayerVersion: 1},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, flagsheet.NewJSONLinesSink(&buf).WriteExposures(context.Background(), exposures))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	var e flagsheet.Exposure
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &e))
+	assert.Equal(t, exposures[1], e)
+
+	path := filepath.Join(t.TempDir(), "exposures.jsonl")
+	file, err := flagsheet.NewFileExposureSink(path)
+	assert.NoError(t, err)
+	assert.NoError(t, file.WriteExposures(context.Background(), exposures))
+	assert.NoError(t, file.WriteExposures(context.Background(), exposures[:1]))
+	assert.NoError(t, file.Close())
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(string(data), "\n"))
+
+	var received []flagsheet.Exposure
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer ts.Close()
+	assert.NoError(t, flagsheet.NewWebhookSink(ts.URL).WriteExposures(context.Background(), exposures))
+	assert.Equal(t, exposures, received)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	assert.Error(t, flagsheet.NewWebhookSink(failing.URL).WriteExposures(context.Background(), exposures))
+}
@@ -3,17 +3,25 @@ package flagsheet
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
+	"net/http"
 	"os"
-	"runtime"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/datadog/mmh3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2/google"
 	"gopkg.in/Iwark/spreadsheet.v2"
 )
@@ -25,6 +33,10 @@ type (
 
 const (
 	maxBuckets = 1000
+	// legacyBuckets is the bucket space used before evaluation hashed into all
+	// maxBuckets buckets. Layers marked as legacy keep using it, so that
+	// existing assignments only move when the layer is deliberately migrated.
+	legacyBuckets = 100
 )
 
 type FeatureVariant struct {
@@ -33,36 +45,120 @@ type FeatureVariant struct {
 }
 
 type Feature struct {
-	Key        string
-	LayerName  string
+	Key       string
+	LayerName string
+	Type      VariantType
+	// Default is served to entities outside of the feature's buckets,
+	// e.g. the holdout when weights sum to less than 1000.
+	Default    FeatureValue
 	VariantMap map[string]FeatureVariant
+	// Targets are checked in sheet order before the layer buckets.
+	// The first target whose rule matches decides the variant.
+	Targets []Target
+}
+
+// Target is a set of variants gated by a targeting rule, e.g.
+// `country in [US, CA]`. Entities matching the rule are bucketed into the
+// target's own variants instead of the layer's, with the same bucket they
+// have in the layer. Targets always use all 1000 buckets, even on legacy
+// layers, as they have no older assignments to preserve.
+type Target struct {
+	Rule       string
+	VariantMap map[string]FeatureVariant
+
+	rule    *rule
+	buckets []FeatureValue
+	cnt     int
+}
+
+// EvaluationContext is what a feature is evaluated for.
+type EvaluationContext struct {
+	// ID is the entity id used for bucketing.
+	// If it is nil, the entity is anonymous and gets a random bucket,
+	// uniformly distributed over the layer, see WithRand.
+	ID *string
+	// Attributes are matched against targeting rules, e.g. country, plan or app_version.
+	// Values should be strings, numbers or bools.
+	Attributes map[string]interface{}
 }
 
 // Layers encompass related and possibly interacting features.
 // For example, if you are testing multiple changes to the signup screen,
 // you should group them into a signup layer, as they can interact.
+// Features in a layer never share a bucket, so an entity is in at most
+// one of them. Targeted flag rows are the exception: they have their own
+// buckets and are served whenever the rule matches, regardless of which
+// feature owns the entity's layer bucket.
 type Layer struct {
 	Name    string
 	Version int
+	// Legacy layers only hash into the first 100 buckets, which reproduces
+	// assignments made by older versions of this library.
+	Legacy bool
 	// buckets maps a particular bucket to the feature value.
 	// This is an array of size 1000, where each index is a bucket
 	// and the value is the feature value.
 	// We use an array because it is significantly faster than a map.
 	buckets []FeatureValue
+	// owners maps a bucket to the key of the feature that filled it.
+	owners []string
 	// cnt is an internal tracker of how many buckets have been filled.
 	cnt int
 }
 
+// snapshot is an immutable, consistent view of the configuration.
+// Refresh builds a new snapshot and swaps it in, so evaluation never sees
+// features from one refresh with layers from another.
+type snapshot struct {
+	lmap map[string]Layer
+	fmap map[string]Feature
+	// omap maps feature key to entity id to the pinned variant.
+	omap map[string]map[string]FeatureValue
+	// version identifies the loaded tables, see tablesVersion.
+	version string
+	// revision is the source revision the tables were fetched at, if the
+	// source is a Revisioner.
+	revision string
+	// tables are the raw tables the snapshot was parsed from.
+	tables *Tables
+	// savedAt is when the tables were first loaded.
+	savedAt time.Time
+}
+
 // flagSheet is an internal representation for goroutine purposes.
 type flagSheet struct {
-	sheetID    string
-	service    *spreadsheet.Service
-	expiration time.Duration
+	source       Source
+	expiration   time.Duration
+	snapshotPath string
 
-	mu      sync.RWMutex
+	// mu serializes refreshes, evaluation only loads the current snapshot.
+	mu      sync.Mutex
 	janitor *janitor
-	lmap    map[string]Layer
-	fmap    map[string]Feature
+	current atomic.Pointer[snapshot]
+	// stale is set while serving a snapshot file because the source failed.
+	stale atomic.Bool
+
+	maxStaleness time.Duration
+	maxBackoff   time.Duration
+	metrics      Metrics
+	exposures    ExposureLogger
+	// rand picks the buckets of entities without an id.
+	rand   bucketRand
+	tracer trace.Tracer
+	// status tracks refresh outcomes, see refreshStatus.
+	statusMu sync.RWMutex
+	status   refreshStatus
+	// subs are the Subscribe callbacks by id.
+	subsMu  sync.Mutex
+	subs    map[int]func(ChangeEvent)
+	nextSub int
+}
+
+// refreshStatus tracks the outcome of recent refreshes.
+type refreshStatus struct {
+	lastSuccess time.Time
+	lastErr     error
+	failures    int
 }
 
 type FlagSheet struct {
@@ -72,36 +168,115 @@ type FlagSheet struct {
 // Evaluate returns the feature variant for a given flagName and id
 // if the feature does not exist, it returns an empty string and false
 func (f *flagSheet) Evaluate(key string, id *string) (FeatureValue, error) {
-	feature, ok := f.fmap[key]
+	return f.EvaluateContext(key, EvaluationContext{ID: id})
+}
+
+// EvaluateContext returns the feature variant for an evaluation context,
+// applying the feature's targeting rules before the layer buckets.
+func (f *flagSheet) EvaluateContext(key string, ectx EvaluationContext) (FeatureValue, error) {
+	d, err := f.EvaluateDetail(key, ectx)
+	return d.Value, err
+}
+
+// EvaluateDetail evaluates a feature and returns the variant with its type.
+func (f *flagSheet) EvaluateDetail(key string, ectx EvaluationContext) (EvaluationDetail, error) {
+	return f.evaluate(f.current.Load(), key, ectx)
+}
+
+// Explain evaluates a feature like EvaluateDetail, but without recording it
+// in the metrics or the exposure log, so debugging doesn't skew experiments.
+func (f *flagSheet) Explain(key string, ectx EvaluationContext) (EvaluationDetail, error) {
+	return f.current.Load().evaluate(key, ectx, f.rand)
+}
+
+// evaluate evaluates a feature against the snapshot, which is nil until the
+// first configuration is loaded. rnd picks the bucket if ectx has no id.
+func (snap *snapshot) evaluate(key string, ectx EvaluationContext, rnd bucketRand) (EvaluationDetail, error) {
+	detail := EvaluationDetail{
+		Key:    key,
+		Reason: ReasonError,
+		Bucket: -1,
+	}
+	if snap == nil {
+		return detail, ErrNotReady
+	}
+	detail.SnapshotVersion = snap.version
+	feature, ok := snap.fmap[key]
 	if !ok {
-		return "", fmt.Errorf("feature %s not found", key)
+		return detail, &KeyError{Key: key, Err: ErrFeatureNotFound}
+	}
+	detail.Type = feature.Type
+	detail.Layer = feature.LayerName
+	// overrides pin an entity regardless of its bucket
+	if ectx.ID != nil {
+		if fv, ok := snap.omap[key][*ectx.ID]; ok {
+			detail.Value = fv
+			detail.Reason = ReasonOverride
+			return detail, nil
+		}
 	}
 	// get the layer -- this should not error
-	layer, ok := f.lmap[feature.LayerName]
+	layer, ok := snap.lmap[feature.LayerName]
 	if !ok {
-		return "", fmt.Errorf("layer %s not found", feature.LayerName)
+		return detail, &KeyError{Key: feature.LayerName, Err: ErrLayerNotFound}
+	}
+	detail.LayerVersion = layer.Version
+	bucket := layer.bucket(ectx.ID, rnd)
+	detail.Bucket = bucket
+	for _, target := range feature.Targets {
+		if target.rule.Match(ectx.Attributes) {
+			detail.Rule = target.Rule
+			if layer.Legacy {
+				bucket = layer.hashBucket(ectx.ID, rnd, maxBuckets)
+				detail.Bucket = bucket
+			}
+			if bucket >= target.cnt {
+				return feature.defaultDetail(detail), nil
+			}
+			detail.Value = target.buckets[bucket]
+			detail.Reason = ReasonTargetingMatch
+			return detail, nil
+		}
+	}
+	// the bucket may be empty or belong to another feature in the layer
+	if layer.owners[bucket] != key {
+		return feature.defaultDetail(detail), nil
 	}
-	// get the bucket - essentially hash(id) % 100
-	var bucket int
+	// get the feature value
+	detail.Value = layer.buckets[bucket]
+	detail.Reason = ReasonBucket
+	return detail, nil
+}
 
-	// if id is nil, pick a random number
+func (f Feature) defaultDetail(detail EvaluationDetail) EvaluationDetail {
+	detail.Value = f.Default
+	detail.Reason = ReasonDefault
+	return detail
+}
+
+// bucket returns the bucket an id falls into, essentially hash(id) % 1000.
+// If id is nil, it picks a bucket with rnd, uniformly over the layer's bucket
+// space, so that an anonymous entity gets each variant with the same
+// probability as a random identified one.
+func (l Layer) bucket(id *string, rnd bucketRand) int {
+	return l.hashBucket(id, rnd, l.Size())
+}
+
+// hashBucket returns the bucket an id falls into among the first space buckets.
+func (l Layer) hashBucket(id *string, rnd bucketRand, space int) int {
 	if id == nil {
-		bucket = rand.Intn(100)
-	} else {
-		// build hash input with bytes.Buffer
-		// this should be very fast
-		var bb bytes.Buffer
-		bb.WriteString(*id)
-		bb.WriteString("-")
-		bb.WriteString(layer.Name)
-		bb.WriteString("-")
-		bb.WriteString(strconv.Itoa(layer.Version))
-		h := mmh3.Hash32(bb.Bytes())
-		bucket = int(h % 100)
+		return rnd.Intn(space)
 	}
-	// get the feature value
-	fv := layer.buckets[bucket]
-	return fv, nil
+	// build hash input with bytes.Buffer
+	// this should be very fast
+	var bb bytes.Buffer
+	bb.WriteString(*id)
+	bb.WriteString("-")
+	bb.WriteString(l.Name)
+	bb.WriteString("-")
+	bb.WriteString(strconv.Itoa(l.Version))
+	h := mmh3.Hash32(bb.Bytes())
+	return int(h % uint32(space))
 }
 
 // EvaluateEnv checks local env for overrides, otherwise calls Evaluate
@@ -112,58 +287,347 @@ func (f *flagSheet) EvaluateEnv(key string, id *string) (FeatureValue, error) {
 	return f.Evaluate(key, id)
 }
 
+// Refresh fetches the tables from the source and swaps in the parsed features and layers.
 func (f *flagSheet) Refresh() error {
-	// get spreadsheet
-	spreadsheet, err := f.service.FetchSpreadsheet(f.sheetID)
+	return f.refresh(context.Background())
+}
+
+func (f *flagSheet) refresh(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ctx, span := f.tracer.Start(ctx, "flagsheet.Refresh")
+	start := time.Now()
+	err := f.load(ctx)
+	now := time.Now()
+	if snap := f.current.Load(); err == nil && snap != nil {
+		span.SetAttributes(attribute.String("flagsheet.version", snap.version))
+	}
+	endSpan(span, err)
+	f.statusMu.Lock()
+	if err != nil {
+		f.status.lastErr = err
+		f.status.failures++
+	} else {
+		f.status.lastSuccess = now
+		f.status.lastErr = nil
+		f.status.failures = 0
+	}
+	f.statusMu.Unlock()
+	if f.metrics != nil {
+		f.metrics.Refresh(err, now.Sub(start))
+		if err == nil {
+			f.metrics.Snapshot(f.current.Load().stats(now))
+		}
+	}
+	return err
+}
+
+// load fetches and swaps in the tables, f.mu must be held.
+// It skips the work when the source reports an unchanged revision, or
+// the fetched tables have the same version as the loaded ones.
+func (f *flagSheet) load(ctx context.Context) error {
+	prev := f.current.Load()
+	span := trace.SpanFromContext(ctx)
+	var revision string
+	if r, ok := f.source.(Revisioner); ok {
+		rctx, rspan := f.tracer.Start(ctx, "flagsheet.Revision")
+		var err error
+		revision, err = r.Revision(rctx)
+		endSpan(rspan, err)
+		switch {
+		case err != nil:
+			// fetching tells us whether the source is really down
+			log.Printf("failed to check source revision: %v", err)
+		case prev != nil && revision != "" && revision == prev.revision:
+			span.SetAttributes(attribute.Bool("flagsheet.unchanged", true))
+			f.stale.Store(false)
+			f.confirmSnapshot(prev)
+			return nil
+		}
+	}
+	fctx, fspan := f.tracer.Start(ctx, "flagsheet.Fetch")
+	tables, err := f.source.Fetch(fctx)
+	endSpan(fspan, err)
 	if err != nil {
-		return fmt.Errorf("failed to fetch spreadsheet: %v", err)
+		return err
 	}
+	version := tablesVersion(tables)
+	if prev != nil && version == prev.version {
+		span.SetAttributes(attribute.Bool("flagsheet.unchanged", true))
+		if revision != prev.revision {
+			unchanged := *prev
+			unchanged.revision = revision
+			f.current.Store(&unchanged)
+		}
+		f.stale.Store(false)
+		f.confirmSnapshot(prev)
+		return nil
+	}
+	_, pspan := f.tracer.Start(ctx, "flagsheet.Parse")
+	snap, err := parseTables(tables, version)
+	endSpan(pspan, err)
+	if err != nil {
+		return err
+	}
+	snap.revision = revision
+	snap.savedAt = time.Now()
+	f.current.Store(snap)
+	f.stale.Store(false)
+	if prev != nil {
+		f.notify(prev, snap)
+	}
+	f.confirmSnapshot(snap)
+	return nil
+}
+
+// confirmSnapshot saves the loaded tables to the snapshot file, if any, so
+// a restart knows how fresh they were even when they have not changed.
+func (f *flagSheet) confirmSnapshot(snap *snapshot) {
+	if f.snapshotPath == "" {
+		return
+	}
+	if err := saveSnapshot(f.snapshotPath, snap.version, snap.savedAt, snap.tables); err != nil {
+		log.Printf("failed to save snapshot file: %v", err)
+	}
+}
+
+// loadSnapshotFile serves the last saved configuration, marking it as stale.
+func (f *flagSheet) loadSnapshotFile() error {
+	sf, err := loadSnapshot(f.snapshotPath)
+	if err != nil {
+		return err
+	}
+	snap, err := parseTables(sf.Tables, sf.Version)
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot file %s: %v", f.snapshotPath, err)
+	}
+	snap.savedAt = sf.SavedAt
+	f.current.Store(snap)
+	f.stale.Store(true)
+	// the flags are as fresh as the last refresh that confirmed them
+	f.statusMu.Lock()
+	f.status.lastSuccess = sf.ConfirmedAt
+	f.statusMu.Unlock()
+	if f.metrics != nil {
+		f.metrics.Snapshot(snap.stats(sf.ConfirmedAt))
+	}
+	log.Printf("serving stale flags from snapshot %s saved at %s", sf.Version, sf.SavedAt.Format(time.RFC3339))
+	return nil
+}
+
+// LastSuccessfulRefresh returns when the flags were last loaded successfully.
+// When starting from a snapshot file, it is when the snapshot was last confirmed.
+func (f *flagSheet) LastSuccessfulRefresh() time.Time {
+	f.statusMu.RLock()
+	defer f.statusMu.RUnlock()
+	return f.status.lastSuccess
+}
+
+// LastError returns the error of the last refresh, or nil if it succeeded.
+func (f *flagSheet) LastError() error {
+	f.statusMu.RLock()
+	defer f.statusMu.RUnlock()
+	return f.status.lastErr
+}
+
+// ConsecutiveFailures returns how many refreshes have failed since the last success.
+func (f *flagSheet) ConsecutiveFailures() int {
+	f.statusMu.RLock()
+	defer f.statusMu.RUnlock()
+	return f.status.failures
+}
+
+// Healthy reports whether the flags are fresher than the max staleness set
+// with WithMaxStaleness. Without a max staleness, it is always true.
+func (f *flagSheet) Healthy() bool {
+	if f.maxStaleness <= 0 {
+		return true
+	}
+	return time.Since(f.LastSuccessfulRefresh()) <= f.maxStaleness
+}
+
+// Tables returns the raw tables of the loaded configuration and their version,
+// or nil if nothing is loaded yet. The tables must not be modified.
+func (f *flagSheet) Tables() (*Tables, string) {
+	snap := f.current.Load()
+	if snap == nil {
+		return nil, ""
+	}
+	return snap.tables, snap.version
+}
+
+// Stale reports whether the flags come from the snapshot file because the
+// source could not be fetched. It is cleared by the next successful refresh.
+func (f *flagSheet) Stale() bool {
+	return f.stale.Load()
+}
+
+// tablesVersion returns the source token, or a hash of the rows for sources
+// without one, so that every loaded configuration has a version.
+func tablesVersion(tables *Tables) string {
+	if tables.Token != "" {
+		return tables.Token
+	}
+	h := sha256.New()
+	for _, table := range [][][]string{tables.Flags, tables.Layers, tables.Overrides} {
+		for _, row := range table {
+			for _, cell := range row {
+				h.Write([]byte(cell))
+				h.Write([]byte{0})
+			}
+			h.Write([]byte{'\n'})
+		}
+		h.Write([]byte{0, 0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isBlank reports whether every cell in the row is empty.
+func isBlank(row []string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// target returns the feature's target for a rule, adding it if needed.
+func (f *Feature) target(expr string) (*Target, error) {
+	for i := range f.Targets {
+		if f.Targets[i].Rule == expr {
+			return &f.Targets[i], nil
+		}
+	}
+	r, err := parseRule(expr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rule for feature %s: %v", f.Key, err)
+	}
+	f.Targets = append(f.Targets, Target{
+		Rule:       expr,
+		VariantMap: make(map[string]FeatureVariant),
+		rule:       r,
+		buckets:    make([]FeatureValue, maxBuckets),
+	})
+	return &f.Targets[len(f.Targets)-1], nil
+}
+
+// validateValues checks that every variant parses as the feature's type.
+func (f *Feature) validateValues() error {
+	variantMaps := []map[string]FeatureVariant{f.VariantMap}
+	for _, target := range f.Targets {
+		variantMaps = append(variantMaps, target.VariantMap)
+	}
+	for _, variants := range variantMaps {
+		for _, variant := range variants {
+			if _, err := parseValue(f.Type, variant.Value); err != nil {
+				return fmt.Errorf("feature %s variant %q is not a %s", f.Key, variant.Value, f.Type)
+			}
+		}
+	}
+	if f.Default != "" {
+		if _, err := parseValue(f.Type, f.Default); err != nil {
+			return fmt.Errorf("feature %s default %q is not a %s", f.Key, f.Default, f.Type)
+		}
+	}
+	return nil
+}
+
+// hasVariant reports whether the feature can serve the value, from its
+// layer rows, its targets or its default.
+func (f *Feature) hasVariant(value FeatureValue) bool {
+	if _, ok := f.VariantMap[string(value)]; ok {
+		return true
+	}
+	for _, target := range f.Targets {
+		if _, ok := target.VariantMap[string(value)]; ok {
+			return true
+		}
+	}
+	return f.Default != "" && value == f.Default
+}
+
+// columnIndex returns the index of an optional column, matched by name
+// case-insensitively against the header row. It returns -1 if there is no such column.
+func columnIndex(header []string, name string) int {
+	for i, col := range header {
+		if strings.EqualFold(strings.TrimSpace(col), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// cellAt returns the trimmed value at index i, or an empty string if the row is too short.
+func cellAt(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func parseTables(tables *Tables, version string) (*snapshot, error) {
 	featureMap := make(map[string]Feature)
 	layerMap := make(map[string]Layer)
 
-	// assume second sheet is layers
-	layerRows := spreadsheet.Sheets[1].Rows
-	for i, row := range layerRows {
-		if i == 0 {
+	legacyCol := -1
+	if len(tables.Layers) > 0 {
+		legacyCol = columnIndex(tables.Layers[0], "Legacy")
+	}
+	for i, row := range tables.Layers {
+		if i == 0 || isBlank(row) {
 			continue
 		}
-		layerName := row[0].Value
-		layerVersion, err := strconv.Atoi(row[1].Value)
+		if len(row) < 2 {
+			return nil, fmt.Errorf("layer row %d must have a name and a version", i+1)
+		}
+		layerName := row[0]
+		layerVersion, err := strconv.Atoi(row[1])
 		if err != nil {
-			return fmt.Errorf("failed to parse layer version - must be int: %v", err)
+			return nil, fmt.Errorf("failed to parse layer version - must be int: %v", err)
+		}
+		var legacy bool
+		if v := cellAt(row, legacyCol); v != "" {
+			legacy, err = strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse legacy flag for layer %s - must be bool: %v", layerName, err)
+			}
 		}
 		layerMap[layerName] = Layer{
 			Name:    layerName,
 			Version: layerVersion,
+			Legacy:  legacy,
 			buckets: make([]FeatureValue, maxBuckets),
+			owners:  make([]string, maxBuckets),
 		}
 	}
 
-	// assume first sheet is flags
-	rows := spreadsheet.Sheets[0].Rows
-	for i, row := range rows {
-		if i == 0 {
+	ruleCol, typeCol, defaultCol := -1, -1, -1
+	if len(tables.Flags) > 0 {
+		ruleCol = columnIndex(tables.Flags[0], "Rule")
+		typeCol = columnIndex(tables.Flags[0], "Type")
+		defaultCol = columnIndex(tables.Flags[0], "Default")
+	}
+	// typed is the features with an explicit Type
+	typed := make(map[string]bool)
+	for i, row := range tables.Flags {
+		if i == 0 || isBlank(row) {
 			continue
 		}
-		featureKey := row[0].Value
-		layerName := row[1].Value
-		featureVariantKey := row[2].Value
-		pct, err := strconv.Atoi(row[3].Value)
+		if len(row) < 4 {
+			return nil, fmt.Errorf("flag row %d must have a key, layer, value and weight", i+1)
+		}
+		featureKey := row[0]
+		layerName := row[1]
+		featureVariantKey := row[2]
+		pct, err := strconv.Atoi(row[3])
 		if err != nil {
-			return fmt.Errorf("failed to parse percentage - must be int: %v", err)
+			return nil, fmt.Errorf("failed to parse percentage - must be int: %v", err)
 		}
 		// get layer
 		layer, ok := layerMap[layerName]
 		if !ok {
-			return fmt.Errorf("layer %s does not exist", layerName)
-		}
-		if pct+layer.cnt > maxBuckets {
-			return fmt.Errorf("layer %s does not have enough buckets", layerName)
-		}
-		// add to layer
-		for i := 0; i < pct; i++ {
-			layer.buckets[layer.cnt] = FeatureValue(featureVariantKey)
-			layer.cnt++
+			return nil, fmt.Errorf("layer %s does not exist", layerName)
 		}
 		// add to feature map
 		feature, ok := featureMap[featureKey]
@@ -171,83 +635,235 @@ func (f *flagSheet) Refresh() error {
 			feature = Feature{
 				Key:        featureKey,
 				LayerName:  layerName,
+				Type:       TypeString,
 				VariantMap: make(map[string]FeatureVariant),
 			}
 		}
-		feature.VariantMap[featureVariantKey] = FeatureVariant{
+		if v := cellAt(row, typeCol); v != "" {
+			t, err := parseVariantType(v)
+			if err != nil {
+				return nil, fmt.Errorf("feature %s: %v", featureKey, err)
+			}
+			// string is also the default, so only compare types that were set
+			if typed[featureKey] && t != feature.Type {
+				return nil, fmt.Errorf("feature %s has conflicting types %s and %s", featureKey, feature.Type, t)
+			}
+			feature.Type = t
+			typed[featureKey] = true
+		}
+		if v := cellAt(row, defaultCol); v != "" {
+			if feature.Default != "" && feature.Default != FeatureValue(v) {
+				return nil, fmt.Errorf("feature %s has conflicting defaults %s and %s", featureKey, feature.Default, v)
+			}
+			feature.Default = FeatureValue(v)
+		}
+		// rows with the same value add up
+		variant := FeatureVariant{
 			Value:      FeatureValue(featureVariantKey),
 			Percentage: pct,
 		}
+		// targeted rows fill the target's buckets rather than the layer's
+		if expr := cellAt(row, ruleCol); expr != "" {
+			target, err := feature.target(expr)
+			if err != nil {
+				return nil, err
+			}
+			if pct+target.cnt > maxBuckets {
+				return nil, fmt.Errorf("rule %q of feature %s does not have enough buckets", expr, featureKey)
+			}
+			for i := 0; i < pct; i++ {
+				target.buckets[target.cnt] = FeatureValue(featureVariantKey)
+				target.cnt++
+			}
+			variant.Percentage += target.VariantMap[featureVariantKey].Percentage
+			target.VariantMap[featureVariantKey] = variant
+			featureMap[featureKey] = feature
+			continue
+		}
+		if pct+layer.cnt > maxBuckets {
+			return nil, fmt.Errorf("layer %s does not have enough buckets", layerName)
+		}
+		// add to layer
+		for i := 0; i < pct; i++ {
+			layer.buckets[layer.cnt] = FeatureValue(featureVariantKey)
+			layer.owners[layer.cnt] = featureKey
+			layer.cnt++
+		}
+		variant.Percentage += feature.VariantMap[featureVariantKey].Percentage
+		feature.VariantMap[featureVariantKey] = variant
 		featureMap[featureKey] = feature
 		layerMap[layerName] = layer
 	}
 	// validate
 	for _, layer := range layerMap {
 		if layer.cnt > maxBuckets {
-			return fmt.Errorf("layer %s has too many buckets", layer.Name)
+			return nil, fmt.Errorf("layer %s has too many buckets", layer.Name)
 		}
 	}
-	// lock and update
-	f.mu.Lock()
-	f.fmap = featureMap
-	f.lmap = layerMap
-	f.mu.Unlock()
-	return nil
+	for _, feature := range featureMap {
+		if err := feature.validateValues(); err != nil {
+			return nil, err
+		}
+	}
+
+	overrideMap := make(map[string]map[string]FeatureValue)
+	for i, row := range tables.Overrides {
+		if i == 0 || isBlank(row) {
+			continue
+		}
+		if len(row) < 3 {
+			return nil, fmt.Errorf("override row %d must have a key, entity id and variant", i+1)
+		}
+		featureKey, entityID, variant := row[0], row[1], row[2]
+		feature, ok := featureMap[featureKey]
+		if !ok {
+			return nil, fmt.Errorf("override for entity %s: feature %s does not exist", entityID, featureKey)
+		}
+		// variants were type checked with the flags
+		if !feature.hasVariant(FeatureValue(variant)) {
+			return nil, fmt.Errorf("override for entity %s: feature %s has no variant %q", entityID, featureKey, variant)
+		}
+		if _, ok := overrideMap[featureKey]; !ok {
+			overrideMap[featureKey] = make(map[string]FeatureValue)
+		}
+		if _, ok := overrideMap[featureKey][entityID]; ok {
+			return nil, fmt.Errorf("entity %s has more than one override for feature %s", entityID, featureKey)
+		}
+		overrideMap[featureKey][entityID] = FeatureValue(variant)
+	}
+	return &snapshot{
+		lmap:    layerMap,
+		fmap:    featureMap,
+		omap:    overrideMap,
+		version: version,
+		tables:  tables,
+	}, nil
 }
 
 type janitor struct {
 	Interval time.Duration
-	stop     chan bool
+	// MaxBackoff caps the delay between refreshes after failures.
+	MaxBackoff time.Duration
+	cancel     context.CancelFunc
+	// done is closed once Run has returned.
+	done chan struct{}
 }
 
-func (j *janitor) Run(c *flagSheet) {
-	ticker := time.NewTicker(j.Interval)
+// defaultMaxBackoff caps the refresh backoff unless WithMaxBackoff is used.
+const defaultMaxBackoff = 5 * time.Minute
+
+func (j *janitor) Run(ctx context.Context, c *flagSheet) {
+	defer close(j.done)
+	timer := time.NewTimer(j.Interval)
+	defer timer.Stop()
 	for {
 		select {
-		case <-ticker.C:
-			err := c.Refresh()
-			if err != nil {
+		case <-timer.C:
+			err := c.refresh(ctx)
+			if err != nil && ctx.Err() == nil {
 				log.Printf("failed to refresh feature sheet: %v", err)
 			}
-		case <-j.stop:
-			ticker.Stop()
+			timer.Reset(j.delay(c.ConsecutiveFailures()))
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func stopJanitor(c *FlagSheet) {
-	c.janitor.stop <- true
+// delay returns the time until the next refresh. After failures, it backs off
+// exponentially up to MaxBackoff, with jitter so that many servers don't hit
+// a recovering source at the same time.
+func (j *janitor) delay(failures int) time.Duration {
+	if failures == 0 {
+		return j.Interval
+	}
+	backoff := j.Interval
+	for i := 0; i < failures && backoff < j.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > j.MaxBackoff {
+		backoff = j.MaxBackoff
+	}
+	// equal jitter: between half and all of the backoff
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
 }
 
-func runJanitor(c *flagSheet, ci time.Duration) {
+func runJanitor(ctx context.Context, c *flagSheet, ci time.Duration) {
+	ctx, cancel := context.WithCancel(ctx)
+	maxBackoff := c.maxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	if maxBackoff < ci {
+		maxBackoff = ci
+	}
 	j := &janitor{
-		Interval: ci,
-		stop:     make(chan bool),
+		Interval:   ci,
+		MaxBackoff: maxBackoff,
+		cancel:     cancel,
+		done:       make(chan struct{}),
 	}
 	c.janitor = j
-	go j.Run(c)
+	go j.Run(ctx, c)
+}
+
+// Close stops the background refresh and waits for an in-flight refresh to
+// finish, or for ctx to be done. It is safe to call Close more than once.
+func (f *flagSheet) Close(ctx context.Context) error {
+	if f.janitor == nil {
+		return nil
+	}
+	f.janitor.cancel()
+	select {
+	case <-f.janitor.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-func NewFlagSheet(service *spreadsheet.Service, sheetID string, duration time.Duration) (*FlagSheet, error) {
+// NewFlagSheet loads the tables from the source and, if duration is positive,
+// refreshes them in the background at that interval until ctx is canceled or
+// Close is called.
+func NewFlagSheet(ctx context.Context, source Source, duration time.Duration, opts ...Option) (*FlagSheet, error) {
 	fs := &flagSheet{
-		sheetID:    sheetID,
-		service:    service,
+		source:     source,
 		expiration: duration,
+		tracer:     otel.Tracer(tracerName),
+		rand:       defaultRand,
 	}
-	if err := fs.Refresh(); err != nil {
-		return nil, err
+	for _, opt := range opts {
+		opt(fs)
+	}
+	if err := fs.refresh(ctx); err != nil {
+		if fs.snapshotPath == "" {
+			return nil, err
+		}
+		if serr := fs.loadSnapshotFile(); serr != nil {
+			return nil, errors.Join(err, serr)
+		}
+		log.Printf("failed to load flags, falling back to snapshot file: %v", err)
 	}
-	FS := &FlagSheet{fs}
 	if duration > 0 {
-		runJanitor(fs, duration)
-		runtime.SetFinalizer(FS, stopJanitor)
+		runJanitor(ctx, fs, duration)
 	}
-
-	return FS, nil
+	return &FlagSheet{fs}, nil
 }
 
 func NewSpreadsheetServiceFromEnv(ctx context.Context) (*spreadsheet.Service, error) {
+	client, err := NewGoogleClientFromEnv(ctx, spreadsheet.Scope)
+	if err != nil {
+		return nil, err
+	}
+	service := spreadsheet.NewServiceWithClient(client)
+
+	return service, nil
+}
+
+// NewGoogleClientFromEnv returns an http client authorized for scopes with
+// the service account from the GCP_* env vars.
+func NewGoogleClientFromEnv(ctx context.Context, scopes ...string) (*http.Client, error) {
 	if os.Getenv("GCP_PROJECT_ID") == "" {
 		return nil, fmt.Errorf("GCP_PROJECT_ID not set")
 	}
@@ -268,13 +884,9 @@ func NewSpreadsheetServiceFromEnv(ctx context.Context) (*spreadsheet.Service, er
 	if err != nil {
 		return nil, err
 	}
-	conf, err := google.JWTConfigFromJSON(serviceAccountJSONBytes, spreadsheet.Scope)
+	conf, err := google.JWTConfigFromJSON(serviceAccountJSONBytes, scopes...)
 	if err != nil {
 		return nil, err
 	}
-	client := conf.Client(ctx)
-
-	service := spreadsheet.NewServiceWithClient(client)
-
-	return service, nil
+	return conf.Client(ctx), nil
 }
@@ -2,12 +2,23 @@ package flagsheet_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stillmatic/flagsheet"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"golang.org/x/oauth2/google"
 	"gopkg.in/Iwark/spreadsheet.v2"
 )
@@ -18,8 +29,32 @@ func stringPtr(s string) *string {
 
 const testSpreadsheetID = "15_oV5NcvYK7wK3VVD5ol6KVkWHzPLFl22c1QyLYplpU"
 
+// exampleTables mirrors the example sheet from the readme.
+func exampleTables() *flagsheet.Tables {
+	return &flagsheet.Tables{
+		Flags: [][]string{
+			{"Key", "Layer", "Value", "Weight"},
+			{"my_key", "a", "foo", "250"},
+			{"my_key", "a", "bar", "750"},
+			{"my_other_key", "b", "foo", "400"},
+			{"my_other_key", "b", "bar", "100"},
+			{"overlapping_key", "b", "baz", "10"},
+			{"overlapping_key", "b", "car", "10"},
+			{"overlapping_key", "b", "dag", "480"},
+		},
+		Layers: [][]string{
+			{"Layer", "Version"},
+			{"a", "1"},
+			{"b", "2"},
+		},
+	}
+}
+
 func TestSheet(t *testing.T) {
 	data, err := os.ReadFile("client_secret.json")
+	if errors.Is(err, os.ErrNotExist) {
+		t.Skip("client_secret.json not found")
+	}
 	assert.NoError(t, err)
 
 	conf, err := google.JWTConfigFromJSON(data, spreadsheet.Scope)
@@ -27,25 +62,1043 @@ func TestSheet(t *testing.T) {
 
 	client := conf.Client(context.TODO())
 	service := spreadsheet.NewServiceWithClient(client)
-	spreadsheet, err := flagsheet.NewFlagSheet(service, testSpreadsheetID, 1*time.Second)
+	source := flagsheet.NewSheetsSource(service, testSpreadsheetID)
+	spreadsheet, err := flagsheet.NewFlagSheet(context.Background(), source, 1*time.Second)
 	assert.NoError(t, err)
 	assert.NotNil(t, spreadsheet)
 	fv, err := spreadsheet.Evaluate("my_key", stringPtr("my_id"))
 	assert.Nil(t, err)
 	assert.NotEmpty(t, fv)
+	assert.Equal(t, "bar", string(fv))
+}
+
+func TestStaticSource(t *testing.T) {
+	source := flagsheet.NewStaticSource(exampleTables())
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0)
+	assert.NoError(t, err)
+	fv, err := fs.Evaluate("my_key", stringPtr("my_id"))
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", string(fv))
+
+	_, err = fs.Evaluate("missing_key", stringPtr("my_id"))
+	assert.Error(t, err)
+
+	// an updated source is picked up on refresh
+	tables := exampleTables()
+	tables.Flags = tables.Flags[:2]
+	tables.Flags[1][3] = "1000"
+	source.Set(tables)
+	assert.NoError(t, fs.Refresh())
+	_, err = fs.Evaluate("my_other_key", stringPtr("my_id"))
+	assert.Error(t, err)
+	loaded, version := fs.Tables()
+	assert.Equal(t, tables, loaded)
+	assert.NotEmpty(t, version)
+}
+
+func TestLegacyBuckets(t *testing.T) {
+	tables := exampleTables()
+	tables.Layers[0] = append(tables.Layers[0], "Legacy")
+	tables.Layers[1] = append(tables.Layers[1], "TRUE")
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
+	// my_id hashes to bucket 400, or 0 in the old 100 bucket space
+	fv, err := fs.Evaluate("my_key", stringPtr("my_id"))
+	assert.NoError(t, err)
 	assert.Equal(t, "foo", string(fv))
+	// legacy layers never reach past the first 100 buckets
+	for i := 0; i < 1000; i++ {
+		fv, err := fs.Evaluate("my_key", stringPtr(fmt.Sprintf("id-%d", i)))
+		assert.NoError(t, err)
+		assert.Equal(t, "foo", string(fv))
+	}
 }
 
-func BenchmarkEvaluate(b *testing.B) {
-	data, err := os.ReadFile("client_secret.json")
-	assert.NoError(b, err)
+func TestLegacyTargets(t *testing.T) {
+	tables := exampleTables()
+	tables.Layers[0] = append(tables.Layers[0], "Legacy")
+	tables.Layers[1] = append(tables.Layers[1], "TRUE")
+	for i := range tables.Flags {
+		tables.Flags[i] = append(tables.Flags[i], "")
+	}
+	tables.Flags[0][4] = "Rule"
+	tables.Flags = append(tables.Flags,
+		[]string{"my_key", "a", "t1", "500", "beta == true"},
+		[]string{"my_key", "a", "t2", "500", "beta == true"},
+	)
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
+	// targets are split over all 1000 buckets, even on legacy layers
+	counts := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		fv, err := fs.EvaluateContext("my_key", flagsheet.EvaluationContext{
+			ID:         stringPtr(fmt.Sprintf("id-%d", i)),
+			Attributes: map[string]interface{}{"beta": true},
+		})
+		assert.NoError(t, err)
+		counts[string(fv)]++
+	}
+	assert.Len(t, counts, 2)
+	assert.InDelta(t, 1000, counts["t1"], 150)
+	assert.InDelta(t, 1000, counts["t2"], 150)
+}
 
-	conf, err := google.JWTConfigFromJSON(data, spreadsheet.Scope)
-	assert.NoError(b, err)
+// TestBucketDistribution checks that the observed split matches the
+// configured weights with a chi-squared goodness of fit test.
+func TestBucketDistribution(t *testing.T) {
+	tables := exampleTables()
+	tables.Flags = append(tables.Flags,
+		[]string{"partial_key", "c", "x", "100"},
+		[]string{"partial_key", "c", "y", "300"},
+	)
+	tables.Layers = append(tables.Layers, []string{"c", "1"})
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
 
-	client := conf.Client(context.TODO())
-	service := spreadsheet.NewServiceWithClient(client)
-	spreadsheet, err := flagsheet.NewFlagSheet(service, testSpreadsheetID, 1*time.Second)
+	const n = 100000
+	cases := []struct {
+		key     string
+		weights map[string]int
+		// critical value of the chi-squared distribution at p = 0.001
+		critical float64
+	}{
+		{"my_key", map[string]int{"foo": 250, "bar": 750}, 10.83},
+		// buckets filled by other features in the layer return the default
+		{"my_other_key", map[string]int{"foo": 400, "bar": 100, "": 500}, 13.82},
+		{"overlapping_key", map[string]int{"baz": 10, "car": 10, "dag": 480, "": 500}, 16.27},
+		// unfilled buckets return the default too
+		{"partial_key", map[string]int{"x": 100, "y": 300, "": 600}, 13.82},
+	}
+	for _, c := range cases {
+		t.Run(c.key, func(t *testing.T) {
+			counts := make(map[string]int)
+			for i := 0; i < n; i++ {
+				fv, err := fs.Evaluate(c.key, stringPtr(fmt.Sprintf("user-%d", i)))
+				assert.NoError(t, err)
+				counts[string(fv)]++
+			}
+			var chi2 float64
+			for variant, weight := range c.weights {
+				expected := float64(n*weight) / 1000
+				diff := float64(counts[variant]) - expected
+				chi2 += diff * diff / expected
+				delete(counts, variant)
+			}
+			assert.Empty(t, counts, "unexpected variants")
+			assert.Less(t, chi2, c.critical)
+		})
+	}
+}
+
+func TestAnonymousBuckets(t *testing.T) {
+	tables := exampleTables()
+	tables.Flags = append(tables.Flags, []string{"legacy_key", "c", "x", "100"})
+	tables.Layers = append(tables.Layers, []string{"c", "1", "true"})
+	tables.Layers[0] = append(tables.Layers[0], "Legacy")
+	buckets := func(fs *flagsheet.FlagSheet, key string, n int) []int {
+		buckets := make([]int, n)
+		for i := range buckets {
+			d, err := fs.EvaluateDetail(key, flagsheet.EvaluationContext{})
+			assert.NoError(t, err)
+			buckets[i] = d.Bucket
+		}
+		return buckets
+	}
+
+	// seeded sources are reproducible
+	fs1, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0, flagsheet.WithRand(rand.NewSource(1)))
+	assert.NoError(t, err)
+	fs2, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0, flagsheet.WithRand(rand.NewSource(1)))
+	assert.NoError(t, err)
+	assert.Equal(t, buckets(fs1, "my_key", 100), buckets(fs2, "my_key", 100))
+
+	// buckets are uniform over the whole layer, or the first 100 for legacy layers
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
+	const n = 100000
+	for key, size := range map[string]int{"my_key": 1000, "legacy_key": 100} {
+		counts := make([]int, 10)
+		for _, bucket := range buckets(fs, key, n) {
+			assert.True(t, bucket >= 0 && bucket < size)
+			counts[bucket*10/size]++
+		}
+		var chi2 float64
+		for _, count := range counts {
+			diff := float64(count) - n/10
+			chi2 += diff * diff / (n / 10)
+		}
+		// critical value of the chi-squared distribution with 9 degrees of freedom at p = 0.001
+		assert.Less(t, chi2, 27.88, key)
+	}
+}
+
+func TestTargeting(t *testing.T) {
+	tables := exampleTables()
+	for i := range tables.Flags {
+		tables.Flags[i] = append(tables.Flags[i], "")
+	}
+	tables.Flags[0][4] = "Rule"
+	tables.Flags = append(tables.Flags,
+		[]string{"my_key", "a", "employee", "1000", "email_domain == corp.com"},
+		[]string{"my_key", "a", "market", "1000", "country in [US, CA] && app_version >= 3.2.0"},
+		[]string{"my_key", "a", "paid", "1000", "plan not in [free]"},
+		[]string{"my_key", "a", "big", "1000", "seats > 100"},
+		[]string{"my_key", "a", "beta", "1000", "beta == true"},
+	)
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		attrs    map[string]interface{}
+		expected string
+	}{
+		{nil, "bar"},
+		{map[string]interface{}{"email_domain": "corp.com", "country": "US"}, "employee"},
+		{map[string]interface{}{"country": "CA", "app_version": "3.10.0"}, "market"},
+		{map[string]interface{}{"country": "CA", "app_version": "3.1.9"}, "bar"},
+		{map[string]interface{}{"country": "MX", "app_version": "4.0"}, "bar"},
+		{map[string]interface{}{"plan": "pro"}, "paid"},
+		{map[string]interface{}{"plan": "free"}, "bar"},
+		{map[string]interface{}{"seats": 250}, "big"},
+		{map[string]interface{}{"seats": 20.5}, "bar"},
+		{map[string]interface{}{"beta": true}, "beta"},
+		{map[string]interface{}{"beta": "yes"}, "bar"},
+	}
+	for _, c := range cases {
+		fv, err := fs.EvaluateContext("my_key", flagsheet.EvaluationContext{
+			ID:         stringPtr("my_id"),
+			Attributes: c.attrs,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, string(fv), "attributes %v", c.attrs)
+	}
+
+	// rules get their own buckets
+	tables.Flags[len(tables.Flags)-1][3] = "10"
+	fs, err = flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
+	fv, err := fs.EvaluateContext("my_key", flagsheet.EvaluationContext{
+		ID:         stringPtr("my_id"),
+		Attributes: map[string]interface{}{"beta": true},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, fv)
+}
+
+func TestOverrides(t *testing.T) {
+	tables := exampleTables()
+	tables.Overrides = [][]string{
+		{"Key", "EntityID", "Variant"},
+		{"my_key", "qa_user", "foo"},
+		{"my_key", "my_id", "foo"},
+	}
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
+	// my_id would otherwise be bucketed into bar
+	fv, err := fs.Evaluate("my_key", stringPtr("my_id"))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", string(fv))
+	// overrides apply whatever the attributes
+	fv, err = fs.EvaluateContext("my_key", flagsheet.EvaluationContext{
+		ID:         stringPtr("qa_user"),
+		Attributes: map[string]interface{}{"country": "US"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", string(fv))
+	// overrides are per feature
+	fv, err = fs.Evaluate("my_other_key", stringPtr("my_id"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, "foo", string(fv))
+
+	tables.Overrides = append(tables.Overrides, []string{"my_key", "my_id", "bar"})
+	_, err = flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.Error(t, err)
+	tables.Overrides = [][]string{{"Key", "EntityID", "Variant"}, {"typo_key", "my_id", "foo"}}
+	_, err = flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.Error(t, err)
+	// the variant must be one the feature serves
+	tables.Overrides = [][]string{{"Key", "EntityID", "Variant"}, {"my_key", "my_id", "pinned"}}
+	_, err = flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.Error(t, err)
+}
+
+func TestTypedValues(t *testing.T) {
+	tables := &flagsheet.Tables{
+		Flags: [][]string{
+			{"Key", "Layer", "Value", "Weight", "Type"},
+			{"enabled", "a", "true", "1000", "bool"},
+			{"limit", "b", "42", "1000", "int"},
+			{"ratio", "c", "0.5", "1000", "float"},
+			{"config", "d", `{"color": "red"}`, "1000", "json"},
+			{"partial", "e", "7", "1", "int"},
+			{"untyped", "f", "false", "1000", ""},
+		},
+		Layers: [][]string{
+			{"Layer", "Version"},
+			{"a", "1"}, {"b", "1"}, {"c", "1"}, {"d", "1"}, {"e", "1"}, {"f", "1"},
+		},
+	}
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
+	ectx := flagsheet.EvaluationContext{ID: stringPtr("my_id")}
+
+	b, err := fs.EvaluateBool("enabled", ectx, false)
+	assert.NoError(t, err)
+	assert.True(t, b)
+	i, err := fs.EvaluateInt("limit", ectx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), i)
+	f, err := fs.EvaluateFloat("ratio", ectx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, f)
+	var cfg struct{ Color string }
+	assert.NoError(t, fs.EvaluateJSON("config", ectx, &cfg))
+	assert.Equal(t, "red", cfg.Color)
+	// string features can be read as any type that parses
+	b, err = fs.EvaluateBool("untyped", ectx, true)
+	assert.NoError(t, err)
+	assert.False(t, b)
+	d, err := fs.EvaluateDetail("config", ectx)
+	assert.NoError(t, err)
+	v, err := d.Typed()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "red"}, v)
+
+	// defaults
+	i, err = fs.EvaluateInt("partial", ectx, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), i)
+	b, err = fs.EvaluateBool("limit", ectx, true)
+	assert.Error(t, err)
+	assert.True(t, b)
+	f, err = fs.EvaluateFloat("missing", ectx, 2.5)
+	assert.Error(t, err)
+	assert.Equal(t, 2.5, f)
+
+	// variants must parse as the declared type
+	tables.Flags[2][2] = "many"
+	_, err = flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.Error(t, err)
+	tables.Flags[2][2] = "42"
+	tables.Flags[2][4] = "integer"
+	_, err = flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.Error(t, err)
+	tables.Flags[2][4] = "int"
+
+	// rows of a feature must not declare different types, in either order
+	for _, types := range [][2]string{{"string", "int"}, {"int", "string"}} {
+		conflicting := *tables
+		conflicting.Flags = append(append([][]string{}, tables.Flags...),
+			[]string{"both", "e", "1", "1", types[0]},
+			[]string{"both", "e", "2", "1", types[1]},
+		)
+		_, err = flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(&conflicting), 0)
+		assert.ErrorContains(t, err, "conflicting types", "%v", types)
+	}
+	// rows without a type take the feature's type
+	untyped := *tables
+	untyped.Flags = append(append([][]string{}, tables.Flags...),
+		[]string{"mixed", "e", "1", "1", ""},
+		[]string{"mixed", "e", "2", "1", "int"},
+	)
+	_, err = flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(&untyped), 0)
+	assert.NoError(t, err)
+}
+
+func TestDefaults(t *testing.T) {
+	tables := exampleTables()
+	for i := range tables.Flags {
+		tables.Flags[i] = append(tables.Flags[i], "", "")
+	}
+	tables.Flags[0][4], tables.Flags[0][5] = "Default", "Rule"
+	tables.Flags[3][4] = "control"
+	tables.Flags = append(tables.Flags,
+		[]string{"my_key", "a", "beta", "1", "", "beta == true"},
+	)
+	// overrides can pin the default too
+	tables.Overrides = [][]string{
+		{"Key", "EntityID", "Variant"},
+		{"my_key", "qa_user", "foo"},
+		{"my_other_key", "qa_user", "control"},
+	}
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		key    string
+		ectx   flagsheet.EvaluationContext
+		value  string
+		reason flagsheet.Reason
+	}{
+		{"my_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")}, "bar", flagsheet.ReasonBucket},
+		{"my_key", flagsheet.EvaluationContext{ID: stringPtr("qa_user")}, "foo", flagsheet.ReasonOverride},
+		// my_id is in bucket 400, which the single beta bucket doesn't fill
+		{"my_key", flagsheet.EvaluationContext{
+			ID:         stringPtr("my_id"),
+			Attributes: map[string]interface{}{"beta": true},
+		}, "", flagsheet.ReasonDefault},
+		// bucket 845 of layer b belongs to overlapping_key
+		{"my_other_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")}, "control", flagsheet.ReasonDefault},
+		{"my_other_key", flagsheet.EvaluationContext{ID: stringPtr("qa_user")}, "control", flagsheet.ReasonOverride},
+		{"missing_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")}, "", flagsheet.ReasonError},
+	}
+	for _, c := range cases {
+		d, err := fs.EvaluateDetail(c.key, c.ectx)
+		assert.Equal(t, c.reason == flagsheet.ReasonError, err != nil)
+		assert.Equal(t, c.value, string(d.Value), c.key)
+		assert.Equal(t, c.reason, d.Reason, c.key)
+	}
+
+	tables.Flags[4][4] = "treatment"
+	_, err = flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.Error(t, err)
+}
+
+func TestEvaluateDetail(t *testing.T) {
+	tables := exampleTables()
+	tables.Flags[0] = append(tables.Flags[0], "Rule")
+	tables.Flags = append(tables.Flags, []string{"my_key", "a", "foo", "1000", "country == US"})
+	tables.Overrides = [][]string{{"Key", "EntityID", "Variant"}, {"my_key", "qa_user", "bar"}}
+	source := flagsheet.NewStaticSource(tables)
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0)
+	assert.NoError(t, err)
+
+	d, err := fs.EvaluateDetail("my_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")})
+	assert.NoError(t, err)
+	assert.Equal(t, "a", d.Layer)
+	assert.Equal(t, 1, d.LayerVersion)
+	assert.Equal(t, 400, d.Bucket)
+	assert.Empty(t, d.Rule)
+	assert.NotEmpty(t, d.SnapshotVersion)
+	version := d.SnapshotVersion
+
+	d, err = fs.EvaluateDetail("my_key", flagsheet.EvaluationContext{
+		ID:         stringPtr("my_id"),
+		Attributes: map[string]interface{}{"country": "US"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", string(d.Value))
+	assert.Equal(t, flagsheet.ReasonTargetingMatch, d.Reason)
+	assert.Equal(t, "country == US", d.Rule)
+	assert.Equal(t, 400, d.Bucket)
+
+	d, err = fs.EvaluateDetail("my_key", flagsheet.EvaluationContext{ID: stringPtr("qa_user")})
+	assert.NoError(t, err)
+	assert.Equal(t, flagsheet.ReasonOverride, d.Reason)
+	assert.Equal(t, -1, d.Bucket)
+
+	// the snapshot version changes with the configuration
+	assert.NoError(t, fs.Refresh())
+	d, _ = fs.EvaluateDetail("my_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")})
+	assert.Equal(t, version, d.SnapshotVersion)
+	tables = exampleTables()
+	tables.Layers[1][1] = "2"
+	source.Set(tables)
+	assert.NoError(t, fs.Refresh())
+	d, _ = fs.EvaluateDetail("my_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")})
+	assert.NotEqual(t, version, d.SnapshotVersion)
+	assert.Equal(t, 2, d.LayerVersion)
+}
+
+// TestConcurrentRefresh hammers evaluation during refreshes, run it with -race.
+// The two configurations put the feature in different layers, so evaluating
+// features from one refresh against layers from another would fail.
+func TestConcurrentRefresh(t *testing.T) {
+	configs := []*flagsheet.Tables{
+		{
+			Flags:  [][]string{{"Key", "Layer", "Value", "Weight"}, {"my_key", "a", "a1", "1000"}},
+			Layers: [][]string{{"Layer", "Version"}, {"a", "1"}},
+		},
+		{
+			Flags:  [][]string{{"Key", "Layer", "Value", "Weight"}, {"my_key", "b", "b2", "1000"}},
+			Layers: [][]string{{"Layer", "Version"}, {"b", "2"}},
+		},
+	}
+	source := flagsheet.NewStaticSource(configs[0])
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0)
+	assert.NoError(t, err)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				d, err := fs.EvaluateDetail("my_key", flagsheet.EvaluationContext{
+					ID: stringPtr(fmt.Sprintf("user-%d-%d", g, i)),
+				})
+				if !assert.NoError(t, err) {
+					return
+				}
+				expected := fmt.Sprintf("%s%d", d.Layer, d.LayerVersion)
+				if !assert.Equal(t, expected, string(d.Value)) {
+					return
+				}
+			}
+		}(g)
+	}
+	for i := 0; i < 200; i++ {
+		source.Set(configs[i%2])
+		assert.NoError(t, fs.Refresh())
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestClose(t *testing.T) {
+	source := flagsheet.NewStaticSource(exampleTables())
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, time.Millisecond)
+	assert.NoError(t, err)
+	assert.NoError(t, fs.Close(context.Background()))
+	// idempotent
+	assert.NoError(t, fs.Close(context.Background()))
+
+	// refreshing stopped, so new tables are not picked up
+	tables := exampleTables()
+	tables.Flags[2][2] = "changed"
+	source.Set(tables)
+	time.Sleep(10 * time.Millisecond)
+	fv, err := fs.Evaluate("my_key", stringPtr("my_id"))
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", string(fv))
+
+	// canceling the parent context stops refreshing too
+	ctx, cancel := context.WithCancel(context.Background())
+	fs, err = flagsheet.NewFlagSheet(ctx, source, time.Millisecond)
+	assert.NoError(t, err)
+	cancel()
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), time.Second)
+	defer closeCancel()
+	assert.NoError(t, fs.Close(closeCtx))
+
+	// without a refresh interval there is nothing to stop
+	fs, err = flagsheet.NewFlagSheet(context.Background(), source, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, fs.Close(context.Background()))
+}
+
+func TestSnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	source := flagsheet.NewStaticSource(exampleTables())
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0, flagsheet.WithSnapshotFile(path))
+	assert.NoError(t, err)
+	assert.False(t, fs.Stale())
+	assert.FileExists(t, path)
+
+	// the source is down, start from the snapshot
+	source.Set(nil)
+	fs, err = flagsheet.NewFlagSheet(context.Background(), source, 0, flagsheet.WithSnapshotFile(path))
+	assert.NoError(t, err)
+	assert.True(t, fs.Stale())
+	fv, err := fs.Evaluate("my_key", stringPtr("my_id"))
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", string(fv))
+	assert.Error(t, fs.Refresh())
+	assert.True(t, fs.Stale())
+
+	// and recover once it is back
+	source.Set(exampleTables())
+	assert.NoError(t, fs.Refresh())
+	assert.False(t, fs.Stale())
+
+	// without a snapshot, a failing source is an error
+	source.Set(nil)
+	_, err = flagsheet.NewFlagSheet(context.Background(), source, 0, flagsheet.WithSnapshotFile(path+".missing"))
+	assert.Error(t, err)
+	_, err = flagsheet.NewFlagSheet(context.Background(), source, 0)
+	assert.Error(t, err)
+}
+
+func TestRefreshStatus(t *testing.T) {
+	source := flagsheet.NewStaticSource(exampleTables())
+	start := time.Now()
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0, flagsheet.WithMaxStaleness(time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, fs.LastSuccessfulRefresh().Before(start))
+	assert.NoError(t, fs.LastError())
+	assert.Equal(t, 0, fs.ConsecutiveFailures())
+	assert.True(t, fs.Healthy())
+
+	source.Set(nil)
+	assert.Error(t, fs.Refresh())
+	assert.Error(t, fs.Refresh())
+	assert.Error(t, fs.LastError())
+	assert.Equal(t, 2, fs.ConsecutiveFailures())
+	assert.True(t, fs.Healthy())
+
+	source.Set(exampleTables())
+	assert.NoError(t, fs.Refresh())
+	assert.NoError(t, fs.LastError())
+	assert.Equal(t, 0, fs.ConsecutiveFailures())
+
+	// unhealthy once the last success is too old
+	fs, err = flagsheet.NewFlagSheet(context.Background(), source, 0, flagsheet.WithMaxStaleness(time.Nanosecond))
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	assert.False(t, fs.Healthy())
+}
+
+func TestRefreshBackoff(t *testing.T) {
+	source := &countingSource{Source: flagsheet.NewStaticSource(exampleTables())}
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 5*time.Millisecond,
+		flagsheet.WithMaxBackoff(100*time.Millisecond))
+	assert.NoError(t, err)
+	defer fs.Close(context.Background())
+
+	// while failing, the janitor backs off instead of retrying every interval
+	source.fail.Store(true)
+	time.Sleep(300 * time.Millisecond)
+	assert.Less(t, source.fetches.Load(), int64(15))
+	assert.Greater(t, fs.ConsecutiveFailures(), 1)
+
+	// and returns to the interval once the source recovers
+	source.fail.Store(false)
+	assert.Eventually(t, func() bool {
+		return fs.ConsecutiveFailures() == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+// countingSource counts fetches and fails them on demand.
+type countingSource struct {
+	flagsheet.Source
+	fetches atomic.Int64
+	fail    atomic.Bool
+}
+
+func (s *countingSource) Fetch(ctx context.Context) (*flagsheet.Tables, error) {
+	s.fetches.Add(1)
+	if s.fail.Load() {
+		return nil, errors.New("source is down")
+	}
+	return s.Source.Fetch(ctx)
+}
+
+// revisionSource is a countingSource that reports a revision.
+type revisionSource struct {
+	*countingSource
+	mu       sync.Mutex
+	revision string
+}
+
+func (s *revisionSource) Revision(_ context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revision, nil
+}
+
+func (s *revisionSource) setRevision(revision string) {
+	s.mu.Lock()
+	s.revision = revision
+	s.mu.Unlock()
+}
+
+func TestChangeDetection(t *testing.T) {
+	static := flagsheet.NewStaticSource(exampleTables())
+	source := &revisionSource{countingSource: &countingSource{Source: static}, revision: "1"}
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), source.fetches.Load())
+
+	// an unchanged revision skips the fetch
+	assert.NoError(t, fs.Refresh())
+	assert.Equal(t, int64(1), source.fetches.Load())
+
+	tables := exampleTables()
+	tables.Flags[2][2] = "baz"
+	static.Set(tables)
+	source.setRevision("2")
+	assert.NoError(t, fs.Refresh())
+	assert.Equal(t, int64(2), source.fetches.Load())
+	fv, err := fs.Evaluate("my_key", stringPtr("my_id"))
+	assert.NoError(t, err)
+	assert.Equal(t, "baz", string(fv))
+
+	// without revisions, unchanged rows are not parsed again, only
+	// confirmed in the snapshot file
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	static.Set(exampleTables())
+	fs, err = flagsheet.NewFlagSheet(context.Background(), static, 0, flagsheet.WithSnapshotFile(path))
+	assert.NoError(t, err)
+	saved := readSnapshotFile(t, path)
+	assert.NoError(t, os.Remove(path))
+	static.Set(exampleTables())
+	assert.NoError(t, fs.Refresh())
+	confirmed := readSnapshotFile(t, path)
+	assert.True(t, saved.SavedAt.Equal(confirmed.SavedAt))
+	assert.False(t, confirmed.ConfirmedAt.Before(saved.ConfirmedAt))
+	static.Set(tables)
+	assert.NoError(t, fs.Refresh())
+	assert.True(t, readSnapshotFile(t, path).SavedAt.After(saved.SavedAt))
+}
+
+type snapshotTimes struct {
+	SavedAt     time.Time `json:"saved_at"`
+	ConfirmedAt time.Time `json:"confirmed_at"`
+}
+
+func readSnapshotFile(t *testing.T, path string) snapshotTimes {
+	t.Helper()
+	var st snapshotTimes
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &st))
+	return st
+}
+
+func TestSnapshotFileConfirmed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	source := flagsheet.NewStaticSource(exampleTables())
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0, flagsheet.WithSnapshotFile(path))
+	assert.NoError(t, err)
+	assert.NoError(t, fs.Close(context.Background()))
+
+	// age the snapshot past the max staleness
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var sf map[string]any
+	assert.NoError(t, json.Unmarshal(data, &sf))
+	old := time.Now().Add(-2 * time.Hour).UTC()
+	sf["saved_at"], sf["confirmed_at"] = old, old
+	data, err = json.Marshal(sf)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, data, 0o644))
+
+	source.Set(nil)
+	fs, err = flagsheet.NewFlagSheet(context.Background(), source, 0,
+		flagsheet.WithSnapshotFile(path), flagsheet.WithMaxStaleness(time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, fs.Healthy())
+
+	// the source serves the same tables again, confirming the snapshot
+	start := time.Now()
+	source.Set(exampleTables())
+	assert.NoError(t, fs.Refresh())
+	assert.NoError(t, fs.Refresh())
+	assert.True(t, fs.Healthy())
+	assert.NoError(t, fs.Close(context.Background()))
+
+	// a restart is as fresh as the last confirmation, not the first save
+	source.Set(nil)
+	fs, err = flagsheet.NewFlagSheet(context.Background(), source, 0,
+		flagsheet.WithSnapshotFile(path), flagsheet.WithMaxStaleness(time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, fs.Stale())
+	assert.True(t, fs.Healthy())
+	assert.False(t, fs.LastSuccessfulRefresh().Before(start))
+	assert.True(t, readSnapshotFile(t, path).SavedAt.Equal(old))
+	assert.NoError(t, fs.Close(context.Background()))
+}
+
+func TestSubscribe(t *testing.T) {
+	source := flagsheet.NewStaticSource(exampleTables())
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0)
+	assert.NoError(t, err)
+	var events []flagsheet.ChangeEvent
+	unsubscribe := fs.Subscribe(func(e flagsheet.ChangeEvent) {
+		events = append(events, e)
+	})
+
+	// nothing changed
+	source.Set(exampleTables())
+	assert.NoError(t, fs.Refresh())
+	assert.Empty(t, events)
+
+	tables := exampleTables()
+	// resizing my_other_key shifts overlapping_key's buckets
+	tables.Flags[3][3] = "300"
+	tables.Flags = append(tables.Flags, []string{"new_key", "c", "on", "1000"})
+	tables.Layers[1][1] = "2"
+	tables.Layers = append(tables.Layers, []string{"c", "1"})
+	source.Set(tables)
+	assert.NoError(t, fs.Refresh())
+	if assert.Len(t, events, 1) {
+		e := events[0]
+		assert.NotEqual(t, e.PreviousVersion, e.Version)
+		assert.Equal(t, []string{"new_key"}, e.AddedFeatures)
+		assert.Empty(t, e.RemovedFeatures)
+		assert.Equal(t, []string{"my_key", "my_other_key", "overlapping_key"}, e.ChangedFeatures)
+		assert.Equal(t, []string{"c"}, e.AddedLayers)
+		assert.Equal(t, []string{"a"}, e.ChangedLayers)
+	}
+
+	tables = exampleTables()
+	tables.Overrides = [][]string{
+		{"Key", "EntityID", "Variant"},
+		{"my_key", "qa_user", "foo"},
+	}
+	source.Set(tables)
+	assert.NoError(t, fs.Refresh())
+	if assert.Len(t, events, 2) {
+		e := events[1]
+		assert.Equal(t, []string{"new_key"}, e.RemovedFeatures)
+		assert.Equal(t, []string{"my_key", "my_other_key", "overlapping_key"}, e.ChangedFeatures)
+		assert.Equal(t, []string{"c"}, e.RemovedLayers)
+	}
+
+	// swapping target rows moves entities between variants
+	withTargets := func(first, second string) *flagsheet.Tables {
+		tables := exampleTables()
+		for i := range tables.Flags {
+			tables.Flags[i] = append(tables.Flags[i], "")
+		}
+		tables.Flags[0][4] = "Rule"
+		tables.Flags = append(tables.Flags,
+			[]string{"my_key", "a", first, "500", "beta == true"},
+			[]string{"my_key", "a", second, "500", "beta == true"},
+		)
+		return tables
+	}
+	source.Set(withTargets("t1", "t2"))
+	assert.NoError(t, fs.Refresh())
+	assert.Len(t, events, 3)
+	source.Set(withTargets("t2", "t1"))
+	assert.NoError(t, fs.Refresh())
+	if assert.Len(t, events, 4) {
+		assert.Equal(t, []string{"my_key"}, events[3].ChangedFeatures)
+	}
+
+	unsubscribe()
+	source.Set(exampleTables())
+	assert.NoError(t, fs.Refresh())
+	assert.Len(t, events, 4)
+}
+
+func TestBatchEvaluate(t *testing.T) {
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(exampleTables()), 0)
+	assert.NoError(t, err)
+	ectx := flagsheet.EvaluationContext{ID: stringPtr("my_id")}
+
+	results := fs.BatchEvaluate([]flagsheet.EvaluationRequest{
+		{Key: "my_key", Context: ectx},
+		{Key: "missing_key", Context: ectx},
+		{Key: "my_other_key", Context: ectx},
+	})
+	if assert.Len(t, results, 3) {
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, "bar", string(results[0].Value))
+		assert.Error(t, results[1].Err)
+		assert.Equal(t, "missing_key", results[1].Key)
+		assert.Equal(t, flagsheet.ReasonError, results[1].Reason)
+		assert.NoError(t, results[2].Err)
+		assert.Equal(t, "my_other_key", results[2].Key)
+	}
+
+	all, err := fs.EvaluateAll(ectx)
+	assert.NoError(t, err)
+	var keys []string
+	for _, r := range all {
+		assert.NoError(t, r.Err)
+		keys = append(keys, r.Key)
+		d, err := fs.EvaluateDetail(r.Key, ectx)
+		assert.NoError(t, err)
+		assert.Equal(t, d, r.EvaluationDetail)
+	}
+	assert.Equal(t, []string{"my_key", "my_other_key", "overlapping_key"}, keys)
+}
+
+func TestIntrospection(t *testing.T) {
+	tables := exampleTables()
+	tables.Flags[0] = append(tables.Flags[0], "Rule")
+	// rows with the same value add up
+	tables.Flags[1][3] = "150"
+	tables.Flags = append(tables.Flags,
+		[]string{"my_key", "a", "foo", "100", ""},
+		[]string{"my_key", "a", "foo", "400", "country == US"},
+		[]string{"my_key", "a", "foo", "200", "country == US"},
+	)
+	tables.Layers[0] = append(tables.Layers[0], "Legacy")
+	tables.Layers[2] = append(tables.Layers[2], "TRUE")
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
+
+	var keys []string
+	for _, feature := range fs.Features() {
+		keys = append(keys, feature.Key)
+	}
+	assert.Equal(t, []string{"my_key", "my_other_key", "overlapping_key"}, keys)
+
+	feature, ok := fs.Feature("my_key")
+	assert.True(t, ok)
+	assert.Equal(t, "a", feature.LayerName)
+	assert.Equal(t, 1000, feature.Filled())
+	assert.Equal(t, 250, feature.VariantMap["foo"].Percentage)
+	if assert.Len(t, feature.Targets, 1) {
+		assert.Equal(t, "country == US", feature.Targets[0].Rule)
+		assert.Equal(t, 600, feature.Targets[0].Filled())
+		assert.Equal(t, 600, feature.Targets[0].VariantMap["foo"].Percentage)
+	}
+	other, _ := fs.Feature("my_other_key")
+	assert.Equal(t, 500, other.Filled())
+	_, ok = fs.Feature("missing_key")
+	assert.False(t, ok)
+
+	layers := fs.Layers()
+	if assert.Len(t, layers, 2) {
+		assert.Equal(t, "a", layers[0].Name)
+		assert.Equal(t, 1000, layers[0].Size())
+		assert.Equal(t, 1000, layers[0].Filled())
+		assert.Equal(t, "b", layers[1].Name)
+		assert.Equal(t, 2, layers[1].Version)
+		assert.True(t, layers[1].Legacy)
+		assert.Equal(t, 100, layers[1].Size())
+		assert.Equal(t, 1000, layers[1].Filled())
+	}
+}
+
+func TestErrors(t *testing.T) {
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(exampleTables()), 0)
+	assert.NoError(t, err)
+	_, err = fs.Evaluate("missing_key", stringPtr("my_id"))
+	assert.ErrorIs(t, err, flagsheet.ErrFeatureNotFound)
+	assert.NotErrorIs(t, err, flagsheet.ErrNotReady)
+	var kerr *flagsheet.KeyError
+	if assert.ErrorAs(t, err, &kerr) {
+		assert.Equal(t, "missing_key", kerr.Key)
+	}
+	assert.EqualError(t, err, "feature missing_key not found")
+
+	results := fs.BatchEvaluate([]flagsheet.EvaluationRequest{{Key: "missing_key"}})
+	assert.ErrorIs(t, results[0].Err, flagsheet.ErrFeatureNotFound)
+}
+
+// recordingMetrics records the measurements it receives.
+type recordingMetrics struct {
+	mu          sync.Mutex
+	evaluations []flagsheet.EvaluationDetail
+	refreshes   []error
+	snapshots   []flagsheet.SnapshotStats
+}
+
+func (m *recordingMetrics) Evaluation(d flagsheet.EvaluationDetail, _ error, _ time.Duration) {
+	m.mu.Lock()
+	m.evaluations = append(m.evaluations, d)
+	m.mu.Unlock()
+}
+
+func (m *recordingMetrics) Refresh(err error, _ time.Duration) {
+	m.mu.Lock()
+	m.refreshes = append(m.refreshes, err)
+	m.mu.Unlock()
+}
+
+func (m *recordingMetrics) Snapshot(stats flagsheet.SnapshotStats) {
+	m.mu.Lock()
+	m.snapshots = append(m.snapshots, stats)
+	m.mu.Unlock()
+}
+
+func TestMetrics(t *testing.T) {
+	m := &recordingMetrics{}
+	source := flagsheet.NewStaticSource(exampleTables())
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0, flagsheet.WithMetrics(m))
+	assert.NoError(t, err)
+	assert.Equal(t, []error{nil}, m.refreshes)
+	if assert.Len(t, m.snapshots, 1) {
+		assert.Equal(t, 3, m.snapshots[0].Features)
+		assert.Equal(t, 2, m.snapshots[0].Layers)
+		assert.NotEmpty(t, m.snapshots[0].Version)
+		assert.False(t, m.snapshots[0].UpdatedAt.IsZero())
+	}
+
+	_, err = fs.Evaluate("my_key", stringPtr("my_id"))
+	assert.NoError(t, err)
+	_, err = fs.Evaluate("missing_key", stringPtr("my_id"))
+	assert.Error(t, err)
+	fs.BatchEvaluate([]flagsheet.EvaluationRequest{{Key: "my_key"}, {Key: "my_other_key"}})
+	// explaining is not an evaluation
+	_, err = fs.Explain("my_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")})
+	assert.NoError(t, err)
+	if assert.Len(t, m.evaluations, 4) {
+		assert.Equal(t, flagsheet.FeatureValue("bar"), m.evaluations[0].Value)
+		assert.Equal(t, flagsheet.ReasonError, m.evaluations[1].Reason)
+	}
+
+	source.Set(nil)
+	assert.Error(t, fs.Refresh())
+	assert.Len(t, m.refreshes, 2)
+	assert.Error(t, m.refreshes[1])
+	assert.Len(t, m.snapshots, 1)
+}
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	source := flagsheet.NewStaticSource(exampleTables())
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0, flagsheet.WithTracerProvider(tp))
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	names := make(map[string]tracetest.SpanStub)
+	for _, span := range spans {
+		names[span.Name] = span
+	}
+	refresh := names["flagsheet.Refresh"]
+	assert.Len(t, spans, 3)
+	for _, name := range []string{"flagsheet.Fetch", "flagsheet.Parse"} {
+		assert.Equal(t, refresh.SpanContext.SpanID(), names[name].Parent.SpanID(), name)
+	}
+
+	exporter.Reset()
+	source.Set(nil)
+	assert.Error(t, fs.Refresh())
+	spans = exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		for _, span := range spans {
+			assert.Equal(t, codes.Error, span.Status.Code, span.Name)
+		}
+	}
+
+	// evaluations are recorded on the caller's span
+	exporter.Reset()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+	d, err := fs.EvaluateDetail("my_key", flagsheet.EvaluationContext{ID: stringPtr("my_id")})
+	assert.NoError(t, err)
+	flagsheet.TraceEvaluation(ctx, d)
+	span.End()
+	spans = exporter.GetSpans()
+	if assert.Len(t, spans, 1) && assert.Len(t, spans[0].Events, 1) {
+		event := spans[0].Events[0]
+		assert.Equal(t, "feature_flag", event.Name)
+		assert.Contains(t, event.Attributes, attribute.String("feature_flag.key", "my_key"))
+		assert.Contains(t, event.Attributes, attribute.String("feature_flag.variant", "bar"))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]func(*flagsheet.Tables){
+		"bad weight":    func(tb *flagsheet.Tables) { tb.Flags[1][3] = "lots" },
+		"bad version":   func(tb *flagsheet.Tables) { tb.Layers[1][1] = "v1" },
+		"missing layer": func(tb *flagsheet.Tables) { tb.Flags[1][1] = "z" },
+		"overfull":      func(tb *flagsheet.Tables) { tb.Flags[1][3] = "900" },
+		"short row":     func(tb *flagsheet.Tables) { tb.Flags[1] = tb.Flags[1][:3] },
+		"bad rule": func(tb *flagsheet.Tables) {
+			tb.Flags[0] = append(tb.Flags[0], "Rule")
+			tb.Flags[1] = append(tb.Flags[1], "country is US")
+		},
+		"bad legacy": func(tb *flagsheet.Tables) {
+			tb.Layers[0] = append(tb.Layers[0], "Legacy")
+			tb.Layers[1] = append(tb.Layers[1], "sometimes")
+		},
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			tables := exampleTables()
+			mutate(tables)
+			_, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	source := flagsheet.NewStaticSource(exampleTables())
+	spreadsheet, err := flagsheet.NewFlagSheet(context.Background(), source, 0)
 	assert.NoError(b, err)
 	assert.NotNil(b, spreadsheet)
 	b.ResetTimer()
@@ -54,6 +1107,6 @@ func BenchmarkEvaluate(b *testing.B) {
 		fv, err := spreadsheet.Evaluate("my_key", stringPtr("my_id"))
 		assert.Nil(b, err)
 		assert.NotEmpty(b, fv)
-		assert.Equal(b, "foo", string(fv))
+		assert.Equal(b, "bar", string(fv))
 	}
 }
@@ -36,11 +36,53 @@ const (
 	// FlagSheetServiceEvaluateProcedure is the fully-qualified name of the FlagSheetService's Evaluate
 	// RPC.
 	FlagSheetServiceEvaluateProcedure = "/flagsheet.v1.FlagSheetService/Evaluate"
+	// FlagSheetServiceBatchEvaluateProcedure is the fully-qualified name of the FlagSheetService's
+	// BatchEvaluate RPC.
+	FlagSheetServiceBatchEvaluateProcedure = "/flagsheet.v1.FlagSheetService/BatchEvaluate"
+	// FlagSheetServiceEvaluateAllProcedure is the fully-qualified name of the FlagSheetService's
+	// EvaluateAll RPC.
+	FlagSheetServiceEvaluateAllProcedure = "/flagsheet.v1.FlagSheetService/EvaluateAll"
+	// FlagSheetServiceExplainProcedure is the fully-qualified name of the FlagSheetService's Explain
+	// RPC.
+	FlagSheetServiceExplainProcedure = "/flagsheet.v1.FlagSheetService/Explain"
+	// FlagSheetServiceListFeaturesProcedure is the fully-qualified name of the FlagSheetService's
+	// ListFeatures RPC.
+	FlagSheetServiceListFeaturesProcedure = "/flagsheet.v1.FlagSheetService/ListFeatures"
+	// FlagSheetServiceGetFeatureProcedure is the fully-qualified name of the FlagSheetService's
+	// GetFeature RPC.
+	FlagSheetServiceGetFeatureProcedure = "/flagsheet.v1.FlagSheetService/GetFeature"
+	// FlagSheetServiceListLayersProcedure is the fully-qualified name of the FlagSheetService's
+	// ListLayers RPC.
+	FlagSheetServiceListLayersProcedure = "/flagsheet.v1.FlagSheetService/ListLayers"
+	// FlagSheetServiceGetConfigProcedure is the fully-qualified name of the FlagSheetService's
+	// GetConfig RPC.
+	FlagSheetServiceGetConfigProcedure = "/flagsheet.v1.FlagSheetService/GetConfig"
+	// FlagSheetServiceWatchProcedure is the fully-qualified name of the FlagSheetService's Watch RPC.
+	FlagSheetServiceWatchProcedure = "/flagsheet.v1.FlagSheetService/Watch"
 )
 
 // FlagSheetServiceClient is a client for the flagsheet.v1.FlagSheetService service.
 type FlagSheetServiceClient interface {
 	Evaluate(context.Context, *connect_go.Request[v1.EvaluateRequest]) (*connect_go.Response[v1.EvaluateResponse], error)
+	// BatchEvaluate evaluates many features or entities against the same
+	// configuration in one call.
+	BatchEvaluate(context.Context, *connect_go.Request[v1.BatchEvaluateRequest]) (*connect_go.Response[v1.BatchEvaluateResponse], error)
+	// EvaluateAll evaluates every feature for an entity.
+	EvaluateAll(context.Context, *connect_go.Request[v1.EvaluateAllRequest]) (*connect_go.Response[v1.EvaluateAllResponse], error)
+	// Explain evaluates a feature and returns why the entity got its variant.
+	Explain(context.Context, *connect_go.Request[v1.ExplainRequest]) (*connect_go.Response[v1.ExplainResponse], error)
+	// ListFeatures returns every loaded feature.
+	ListFeatures(context.Context, *connect_go.Request[v1.ListFeaturesRequest]) (*connect_go.Response[v1.ListFeaturesResponse], error)
+	// GetFeature returns a loaded feature.
+	GetFeature(context.Context, *connect_go.Request[v1.GetFeatureRequest]) (*connect_go.Response[v1.GetFeatureResponse], error)
+	// ListLayers returns every loaded layer.
+	ListLayers(context.Context, *connect_go.Request[v1.ListLayersRequest]) (*connect_go.Response[v1.ListLayersResponse], error)
+	// GetConfig returns the configuration, so that clients can evaluate
+	// features locally.
+	GetConfig(context.Context, *connect_go.Request[v1.GetConfigRequest]) (*connect_go.Response[v1.GetConfigResponse], error)
+	// Watch sends the current configuration, and then the full configuration
+	// again every time it changes.
+	Watch(context.Context, *connect_go.Request[v1.WatchRequest]) (*connect_go.ServerStreamForClient[v1.WatchResponse], error)
 }
 
 // NewFlagSheetServiceClient constructs a client for the flagsheet.v1.FlagSheetService service. By
@@ -58,12 +100,60 @@ func NewFlagSheetServiceClient(httpClient connect_go.HTTPClient, baseURL string,
 			baseURL+FlagSheetServiceEvaluateProcedure,
 			opts...,
 		),
+		batchEvaluate: connect_go.NewClient[v1.BatchEvaluateRequest, v1.BatchEvaluateResponse](
+			httpClient,
+			baseURL+FlagSheetServiceBatchEvaluateProcedure,
+			opts...,
+		),
+		evaluateAll: connect_go.NewClient[v1.EvaluateAllRequest, v1.EvaluateAllResponse](
+			httpClient,
+			baseURL+FlagSheetServiceEvaluateAllProcedure,
+			opts...,
+		),
+		explain: connect_go.NewClient[v1.ExplainRequest, v1.ExplainResponse](
+			httpClient,
+			baseURL+FlagSheetServiceExplainProcedure,
+			opts...,
+		),
+		listFeatures: connect_go.NewClient[v1.ListFeaturesRequest, v1.ListFeaturesResponse](
+			httpClient,
+			baseURL+FlagSheetServiceListFeaturesProcedure,
+			opts...,
+		),
+		getFeature: connect_go.NewClient[v1.GetFeatureRequest, v1.GetFeatureResponse](
+			httpClient,
+			baseURL+FlagSheetServiceGetFeatureProcedure,
+			opts...,
+		),
+		listLayers: connect_go.NewClient[v1.ListLayersRequest, v1.ListLayersResponse](
+			httpClient,
+			baseURL+FlagSheetServiceListLayersProcedure,
+			opts...,
+		),
+		getConfig: connect_go.NewClient[v1.GetConfigRequest, v1.GetConfigResponse](
+			httpClient,
+			baseURL+FlagSheetServiceGetConfigProcedure,
+			opts...,
+		),
+		watch: connect_go.NewClient[v1.WatchRequest, v1.WatchResponse](
+			httpClient,
+			baseURL+FlagSheetServiceWatchProcedure,
+			opts...,
+		),
 	}
 }
 
 // flagSheetServiceClient implements FlagSheetServiceClient.
 type flagSheetServiceClient struct {
-	evaluate *connect_go.Client[v1.EvaluateRequest, v1.EvaluateResponse]
+	evaluate      *connect_go.Client[v1.EvaluateRequest, v1.EvaluateResponse]
+	batchEvaluate *connect_go.Client[v1.BatchEvaluateRequest, v1.BatchEvaluateResponse]
+	evaluateAll   *connect_go.Client[v1.EvaluateAllRequest, v1.EvaluateAllResponse]
+	explain       *connect_go.Client[v1.ExplainRequest, v1.ExplainResponse]
+	listFeatures  *connect_go.Client[v1.ListFeaturesRequest, v1.ListFeaturesResponse]
+	getFeature    *connect_go.Client[v1.GetFeatureRequest, v1.GetFeatureResponse]
+	listLayers    *connect_go.Client[v1.ListLayersRequest, v1.ListLayersResponse]
+	getConfig     *connect_go.Client[v1.GetConfigRequest, v1.GetConfigResponse]
+	watch         *connect_go.Client[v1.WatchRequest, v1.WatchResponse]
 }
 
 // Evaluate calls flagsheet.v1.FlagSheetService.Evaluate.
@@ -71,9 +161,68 @@ func (c *flagSheetServiceClient) Evaluate(ctx context.Context, req *connect_go.R
 	return c.evaluate.CallUnary(ctx, req)
 }
 
+// BatchEvaluate calls flagsheet.v1.FlagSheetService.BatchEvaluate.
+func (c *flagSheetServiceClient) BatchEvaluate(ctx context.Context, req *connect_go.Request[v1.BatchEvaluateRequest]) (*connect_go.Response[v1.BatchEvaluateResponse], error) {
+	return c.batchEvaluate.CallUnary(ctx, req)
+}
+
+// EvaluateAll calls flagsheet.v1.FlagSheetService.EvaluateAll.
+func (c *flagSheetServiceClient) EvaluateAll(ctx context.Context, req *connect_go.Request[v1.EvaluateAllRequest]) (*connect_go.Response[v1.EvaluateAllResponse], error) {
+	return c.evaluateAll.CallUnary(ctx, req)
+}
+
+// Explain calls flagsheet.v1.FlagSheetService.Explain.
+func (c *flagSheetServiceClient) Explain(ctx context.Context, req *connect_go.Request[v1.ExplainRequest]) (*connect_go.Response[v1.ExplainResponse], error) {
+	return c.explain.CallUnary(ctx, req)
+}
+
+// ListFeatures calls flagsheet.v1.FlagSheetService.ListFeatures.
+func (c *flagSheetServiceClient) ListFeatures(ctx context.Context, req *connect_go.Request[v1.ListFeaturesRequest]) (*connect_go.Response[v1.ListFeaturesResponse], error) {
+	return c.listFeatures.CallUnary(ctx, req)
+}
+
+// GetFeature calls flagsheet.v1.FlagSheetService.GetFeature.
+func (c *flagSheetServiceClient) GetFeature(ctx context.Context, req *connect_go.Request[v1.GetFeatureRequest]) (*connect_go.Response[v1.GetFeatureResponse], error) {
+	return c.getFeature.CallUnary(ctx, req)
+}
+
+// ListLayers calls flagsheet.v1.FlagSheetService.ListLayers.
+func (c *flagSheetServiceClient) ListLayers(ctx context.Context, req *connect_go.Request[v1.ListLayersRequest]) (*connect_go.Response[v1.ListLayersResponse], error) {
+	return c.listLayers.CallUnary(ctx, req)
+}
+
+// GetConfig calls flagsheet.v1.FlagSheetService.GetConfig.
+func (c *flagSheetServiceClient) GetConfig(ctx context.Context, req *connect_go.Request[v1.GetConfigRequest]) (*connect_go.Response[v1.GetConfigResponse], error) {
+	return c.getConfig.CallUnary(ctx, req)
+}
+
+// Watch calls flagsheet.v1.FlagSheetService.Watch.
+func (c *flagSheetServiceClient) Watch(ctx context.Context, req *connect_go.Request[v1.WatchRequest]) (*connect_go.ServerStreamForClient[v1.WatchResponse], error) {
+	return c.watch.CallServerStream(ctx, req)
+}
+
 // FlagSheetServiceHandler is an implementation of the flagsheet.v1.FlagSheetService service.
 type FlagSheetServiceHandler interface {
 	Evaluate(context.Context, *connect_go.Request[v1.EvaluateRequest]) (*connect_go.Response[v1.EvaluateResponse], error)
+	// BatchEvaluate evaluates many features or entities against the same
+	// configuration in one call.
+	BatchEvaluate(context.Context, *connect_go.Request[v1.BatchEvaluateRequest]) (*connect_go.Response[v1.BatchEvaluateResponse], error)
+	// EvaluateAll evaluates every feature for an entity.
+	EvaluateAll(context.Context, *connect_go.Request[v1.EvaluateAllRequest]) (*connect_go.Response[v1.EvaluateAllResponse], error)
+	// Explain evaluates a feature and returns why the entity got its variant.
+	Explain(context.Context, *connect_go.Request[v1.ExplainRequest]) (*connect_go.Response[v1.ExplainResponse], error)
+	// ListFeatures returns every loaded feature.
+	ListFeatures(context.Context, *connect_go.Request[v1.ListFeaturesRequest]) (*connect_go.Response[v1.ListFeaturesResponse], error)
+	// GetFeature returns a loaded feature.
+	GetFeature(context.Context, *connect_go.Request[v1.GetFeatureRequest]) (*connect_go.Response[v1.GetFeatureResponse], error)
+	// ListLayers returns every loaded layer.
+	ListLayers(context.Context, *connect_go.Request[v1.ListLayersRequest]) (*connect_go.Response[v1.ListLayersResponse], error)
+	// GetConfig returns the configuration, so that clients can evaluate
+	// features locally.
+	GetConfig(context.Context, *connect_go.Request[v1.GetConfigRequest]) (*connect_go.Response[v1.GetConfigResponse], error)
+	// Watch sends the current configuration, and then the full configuration
+	// again every time it changes.
+	Watch(context.Context, *connect_go.Request[v1.WatchRequest], *connect_go.ServerStream[v1.WatchResponse]) error
 }
 
 // NewFlagSheetServiceHandler builds an HTTP handler from the service implementation. It returns the
@@ -88,6 +237,46 @@ func NewFlagSheetServiceHandler(svc FlagSheetServiceHandler, opts ...connect_go.
 		svc.Evaluate,
 		opts...,
 	))
+	mux.Handle(FlagSheetServiceBatchEvaluateProcedure, connect_go.NewUnaryHandler(
+		FlagSheetServiceBatchEvaluateProcedure,
+		svc.BatchEvaluate,
+		opts...,
+	))
+	mux.Handle(FlagSheetServiceEvaluateAllProcedure, connect_go.NewUnaryHandler(
+		FlagSheetServiceEvaluateAllProcedure,
+		svc.EvaluateAll,
+		opts...,
+	))
+	mux.Handle(FlagSheetServiceExplainProcedure, connect_go.NewUnaryHandler(
+		FlagSheetServiceExplainProcedure,
+		svc.Explain,
+		opts...,
+	))
+	mux.Handle(FlagSheetServiceListFeaturesProcedure, connect_go.NewUnaryHandler(
+		FlagSheetServiceListFeaturesProcedure,
+		svc.ListFeatures,
+		opts...,
+	))
+	mux.Handle(FlagSheetServiceGetFeatureProcedure, connect_go.NewUnaryHandler(
+		FlagSheetServiceGetFeatureProcedure,
+		svc.GetFeature,
+		opts...,
+	))
+	mux.Handle(FlagSheetServiceListLayersProcedure, connect_go.NewUnaryHandler(
+		FlagSheetServiceListLayersProcedure,
+		svc.ListLayers,
+		opts...,
+	))
+	mux.Handle(FlagSheetServiceGetConfigProcedure, connect_go.NewUnaryHandler(
+		FlagSheetServiceGetConfigProcedure,
+		svc.GetConfig,
+		opts...,
+	))
+	mux.Handle(FlagSheetServiceWatchProcedure, connect_go.NewServerStreamHandler(
+		FlagSheetServiceWatchProcedure,
+		svc.Watch,
+		opts...,
+	))
 	return "/flagsheet.v1.FlagSheetService/", mux
 }
 
@@ -97,3 +286,35 @@ type UnimplementedFlagSheetServiceHandler struct{}
 func (UnimplementedFlagSheetServiceHandler) Evaluate(context.Context, *connect_go.Request[v1.EvaluateRequest]) (*connect_go.Response[v1.EvaluateResponse], error) {
 	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("flagsheet.v1.FlagSheetService.Evaluate is not implemented"))
 }
+
+func (UnimplementedFlagSheetServiceHandler) BatchEvaluate(context.Context, *connect_go.Request[v1.BatchEvaluateRequest]) (*connect_go.Response[v1.BatchEvaluateResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("flagsheet.v1.FlagSheetService.BatchEvaluate is not implemented"))
+}
+
+func (UnimplementedFlagSheetServiceHandler) EvaluateAll(context.Context, *connect_go.Request[v1.EvaluateAllRequest]) (*connect_go.Response[v1.EvaluateAllResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("flagsheet.v1.FlagSheetService.EvaluateAll is not implemented"))
+}
+
+func (UnimplementedFlagSheetServiceHandler) Explain(context.Context, *connect_go.Request[v1.ExplainRequest]) (*connect_go.Response[v1.ExplainResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("flagsheet.v1.FlagSheetService.Explain is not implemented"))
+}
+
+func (UnimplementedFlagSheetServiceHandler) ListFeatures(context.Context, *connect_go.Request[v1.ListFeaturesRequest]) (*connect_go.Response[v1.ListFeaturesResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("flagsheet.v1.FlagSheetService.ListFeatures is not implemented"))
+}
+
+func (UnimplementedFlagSheetServiceHandler) GetFeature(context.Context, *connect_go.Request[v1.GetFeatureRequest]) (*connect_go.Response[v1.GetFeatureResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("flagsheet.v1.FlagSheetService.GetFeature is not implemented"))
+}
+
+func (UnimplementedFlagSheetServiceHandler) ListLayers(context.Context, *connect_go.Request[v1.ListLayersRequest]) (*connect_go.Response[v1.ListLayersResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("flagsheet.v1.FlagSheetService.ListLayers is not implemented"))
+}
+
+func (UnimplementedFlagSheetServiceHandler) GetConfig(context.Context, *connect_go.Request[v1.GetConfigRequest]) (*connect_go.Response[v1.GetConfigResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("flagsheet.v1.FlagSheetService.GetConfig is not implemented"))
+}
+
+func (UnimplementedFlagSheetServiceHandler) Watch(context.Context, *connect_go.Request[v1.WatchRequest], *connect_go.ServerStream[v1.WatchResponse]) error {
+	return connect_go.NewError(connect_go.CodeUnimplemented, errors.New("flagsheet.v1.FlagSheetService.Watch is not implemented"))
+}
@@ -0,0 +1,75 @@
+package flagsheet
+
+import "sort"
+
+// Features returns the loaded features, sorted by key.
+// The features share their variant maps with the loaded configuration,
+// so they must not be modified.
+func (f *flagSheet) Features() []Feature {
+	snap := f.current.Load()
+	if snap == nil {
+		return nil
+	}
+	features := make([]Feature, 0, len(snap.fmap))
+	for _, feature := range snap.fmap {
+		features = append(features, feature)
+	}
+	sort.Slice(features, func(i, j int) bool {
+		return features[i].Key < features[j].Key
+	})
+	return features
+}
+
+// Feature returns a loaded feature, which must not be modified.
+func (f *flagSheet) Feature(key string) (Feature, bool) {
+	snap := f.current.Load()
+	if snap == nil {
+		return Feature{}, false
+	}
+	feature, ok := snap.fmap[key]
+	return feature, ok
+}
+
+// Layers returns the loaded layers, sorted by name.
+func (f *flagSheet) Layers() []Layer {
+	snap := f.current.Load()
+	if snap == nil {
+		return nil
+	}
+	layers := make([]Layer, 0, len(snap.lmap))
+	for _, layer := range snap.lmap {
+		layers = append(layers, layer)
+	}
+	sort.Slice(layers, func(i, j int) bool {
+		return layers[i].Name < layers[j].Name
+	})
+	return layers
+}
+
+// Filled returns how many of the layer's buckets the feature fills.
+func (f Feature) Filled() int {
+	filled := 0
+	for _, variant := range f.VariantMap {
+		filled += variant.Percentage
+	}
+	return filled
+}
+
+// Filled returns how many of the target's buckets are filled.
+func (t Target) Filled() int {
+	return t.cnt
+}
+
+// Size returns how many buckets entities are hashed into:
+// 1000, or 100 for legacy layers.
+func (l Layer) Size() int {
+	if l.Legacy {
+		return legacyBuckets
+	}
+	return maxBuckets
+}
+
+// Filled returns how many of the layer's buckets are filled by its features.
+func (l Layer) Filled() int {
+	return l.cnt
+}
@@ -0,0 +1,53 @@
+package flagsheet
+
+import "time"
+
+// Metrics receives measurements from a FlagSheet, see WithMetrics.
+// Implementations must be safe for concurrent use and fast, as Evaluation is
+// called on every evaluation. The metrics package exports them to Prometheus.
+type Metrics interface {
+	// Evaluation is called after every evaluation, with its latency.
+	Evaluation(d EvaluationDetail, err error, latency time.Duration)
+	// Refresh is called after every refresh, with its duration.
+	Refresh(err error, duration time.Duration)
+	// Snapshot is called after every successful refresh, and when starting
+	// from the snapshot file.
+	Snapshot(stats SnapshotStats)
+}
+
+// SnapshotStats describes the loaded configuration.
+type SnapshotStats struct {
+	Version  string
+	Features int
+	Layers   int
+	// UpdatedAt is when the configuration was last known to match the
+	// source, i.e. the last successful refresh, or when the snapshot file was
+	// saved if that is what is being served.
+	UpdatedAt time.Time
+}
+
+func (s *snapshot) stats(updatedAt time.Time) SnapshotStats {
+	return SnapshotStats{
+		Version:   s.version,
+		Features:  len(s.fmap),
+		Layers:    len(s.lmap),
+		UpdatedAt: updatedAt,
+	}
+}
+
+// evaluate evaluates a feature against snap, reporting it to the metrics and
+// the exposure logger.
+func (f *flagSheet) evaluate(snap *snapshot, key string, ectx EvaluationContext) (EvaluationDetail, error) {
+	if f.metrics == nil && f.exposures == nil {
+		return snap.evaluate(key, ectx, f.rand)
+	}
+	start := time.Now()
+	d, err := snap.evaluate(key, ectx, f.rand)
+	if f.metrics != nil {
+		f.metrics.Evaluation(d, err, time.Since(start))
+	}
+	if f.exposures != nil && err == nil {
+		f.logExposure(d, ectx, start)
+	}
+	return d, err
+}
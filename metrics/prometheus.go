@@ -0,0 +1,109 @@
+// Package metrics exports flagsheet measurements to Prometheus.
+//
+//	m := metrics.NewPrometheus(prometheus.DefaultRegisterer)
+//	fs, err := flagsheet.NewFlagSheet(ctx, source, 10*time.Second, flagsheet.WithMetrics(m))
+package metrics
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stillmatic/flagsheet"
+)
+
+// Prometheus implements flagsheet.Metrics with Prometheus collectors.
+//
+// Evaluations are counted by feature, variant and reason, so keep variants
+// short: long JSON variants make for long label values. Evaluations of
+// features that aren't loaded are counted under the "_unknown" feature.
+type Prometheus struct {
+	evaluations    *prometheus.CounterVec
+	evaluationTime prometheus.Histogram
+	refreshes      *prometheus.CounterVec
+	refreshTime    prometheus.Histogram
+	features       prometheus.Gauge
+	layers         prometheus.Gauge
+	// updatedAt is SnapshotStats.UpdatedAt in unix nanoseconds, 0 until loaded.
+	updatedAt atomic.Int64
+}
+
+// NewPrometheus creates the collectors and registers them with reg.
+// It panics if they are already registered.
+func NewPrometheus(reg prometheus.Registerer) *Prometheus {
+	m := &Prometheus{
+		evaluations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "flagsheet_evaluations_total",
+			Help: "Feature evaluations by feature, variant and reason.",
+		}, []string{"feature", "variant", "reason"}),
+		evaluationTime: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "flagsheet_evaluation_duration_seconds",
+			Help: "Time spent evaluating a feature.",
+			// evaluations take well under a microsecond
+			Buckets: prometheus.ExponentialBuckets(50e-9, 4, 10),
+		}),
+		refreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "flagsheet_refreshes_total",
+			Help: "Configuration refreshes by result, success or failure.",
+		}, []string{"result"}),
+		refreshTime: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "flagsheet_refresh_duration_seconds",
+			Help:    "Time spent refreshing the configuration.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+		}),
+		features: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "flagsheet_features",
+			Help: "Number of loaded features.",
+		}),
+		layers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "flagsheet_layers",
+			Help: "Number of loaded layers.",
+		}),
+	}
+	snapshotAge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "flagsheet_snapshot_age_seconds",
+		Help: "Time since the loaded configuration was last known to match the source.",
+	}, m.snapshotAge)
+	reg.MustRegister(m.evaluations, m.evaluationTime, m.refreshes, m.refreshTime,
+		m.features, m.layers, snapshotAge)
+	return m
+}
+
+// unknownFeature is the feature label of evaluations of features that aren't
+// loaded, so that callers can't create a series per key they make up.
+const unknownFeature = "_unknown"
+
+func (m *Prometheus) Evaluation(d flagsheet.EvaluationDetail, err error, latency time.Duration) {
+	feature := d.Key
+	if errors.Is(err, flagsheet.ErrFeatureNotFound) || errors.Is(err, flagsheet.ErrNotReady) {
+		feature = unknownFeature
+	}
+	m.evaluations.WithLabelValues(feature, string(d.Value), string(d.Reason)).Inc()
+	m.evaluationTime.Observe(latency.Seconds())
+}
+
+func (m *Prometheus) Refresh(err error, duration time.Duration) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.refreshes.WithLabelValues(result).Inc()
+	m.refreshTime.Observe(duration.Seconds())
+}
+
+func (m *Prometheus) Snapshot(stats flagsheet.SnapshotStats) {
+	m.features.Set(float64(stats.Features))
+	m.layers.Set(float64(stats.Layers))
+	m.updatedAt.Store(stats.UpdatedAt.UnixNano())
+}
+
+// snapshotAge is NaN until a configuration is loaded.
+func (m *Prometheus) snapshotAge() float64 {
+	updatedAt := m.updatedAt.Load()
+	if updatedAt == 0 {
+		return math.NaN()
+	}
+	return time.Since(time.Unix(0, updatedAt)).Seconds()
+}
@@ -0,0 +1,69 @@
+package metrics_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stillmatic/flagsheet"
+	"github.com/stillmatic/flagsheet/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheus(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	source := flagsheet.NewStaticSource(&flagsheet.Tables{
+		Flags: [][]string{
+			{"Key", "Layer", "Value", "Weight"},
+			{"my_key", "a", "on", "1000"},
+		},
+		Layers: [][]string{
+			{"Layer", "Version"},
+			{"a", "1"},
+		},
+	})
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0,
+		flagsheet.WithMetrics(metrics.NewPrometheus(reg)))
+	assert.NoError(t, err)
+	id := "my_id"
+	for i := 0; i < 3; i++ {
+		_, err = fs.Evaluate("my_key", &id)
+		assert.NoError(t, err)
+	}
+	// unknown keys share a series
+	_, err = fs.Evaluate("missing_key", &id)
+	assert.Error(t, err)
+	_, err = fs.Evaluate("other_missing_key", &id)
+	assert.Error(t, err)
+	source.Set(nil)
+	assert.Error(t, fs.Refresh())
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP flagsheet_evaluations_total Feature evaluations by feature, variant and reason.
+# TYPE flagsheet_evaluations_total counter
+flagsheet_evaluations_total{feature="_unknown",reason="ERROR",variant=""} 2
+flagsheet_evaluations_total{feature="my_key",reason="BUCKET",variant="on"} 3
+# HELP flagsheet_refreshes_total Configuration refreshes by result, success or failure.
+# TYPE flagsheet_refreshes_total counter
+flagsheet_refreshes_total{result="failure"} 1
+flagsheet_refreshes_total{result="success"} 1
+# HELP flagsheet_features Number of loaded features.
+# TYPE flagsheet_features gauge
+flagsheet_features 1
+# HELP flagsheet_layers Number of loaded layers.
+# TYPE flagsheet_layers gauge
+flagsheet_layers 1
+`), "flagsheet_evaluations_total", "flagsheet_refreshes_total", "flagsheet_features", "flagsheet_layers"))
+	assert.Equal(t, 2, testutil.CollectAndCount(reg, "flagsheet_evaluation_duration_seconds", "flagsheet_refresh_duration_seconds"))
+
+	mfs, err := reg.Gather()
+	assert.NoError(t, err)
+	for _, mf := range mfs {
+		if mf.GetName() == "flagsheet_snapshot_age_seconds" {
+			v := mf.GetMetric()[0].GetGauge().GetValue()
+			assert.True(t, v >= 0 && v < 60, "snapshot age %v", v)
+		}
+	}
+}
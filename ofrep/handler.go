@@ -0,0 +1,246 @@
+// Package ofrep serves flags over the OpenFeature Remote Evaluation Protocol,
+// so that any OFREP provider, e.g. in a browser or mobile app, can evaluate
+// them without generated clients:
+//
+//	mux.Handle(ofrep.Path, ofrep.NewHandler(fs))
+//
+// It implements POST /ofrep/v1/evaluate/flags/{key} to evaluate one flag, and
+// POST /ofrep/v1/evaluate/flags to evaluate every flag, with an ETag so that
+// clients polling with If-None-Match get a 304 while nothing changed.
+package ofrep
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/stillmatic/flagsheet"
+)
+
+// Path is the prefix of every OFREP endpoint.
+const Path = "/ofrep/v1/"
+
+const flagsPath = Path + "evaluate/flags"
+
+// Error codes, as defined by OFREP.
+const (
+	codeParseError     = "PARSE_ERROR"
+	codeFlagNotFound   = "FLAG_NOT_FOUND"
+	codeInvalidContext = "INVALID_CONTEXT"
+	codeGeneral        = "GENERAL"
+)
+
+var reasons = map[flagsheet.Reason]string{
+	flagsheet.ReasonBucket:         "SPLIT",
+	flagsheet.ReasonTargetingMatch: "TARGETING_MATCH",
+	flagsheet.ReasonOverride:       "TARGETING_MATCH",
+	flagsheet.ReasonDefault:        "DEFAULT",
+	flagsheet.ReasonError:          "ERROR",
+}
+
+type request struct {
+	Context map[string]interface{} `json:"context"`
+}
+
+// evaluation is an OFREP evaluation success, or a failure if ErrorCode is set.
+// Value is omitted for empty variants, so that clients use their default.
+type evaluation struct {
+	Key          string                 `json:"key"`
+	Value        interface{}            `json:"value,omitempty"`
+	Reason       string                 `json:"reason,omitempty"`
+	Variant      string                 `json:"variant,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	ErrorCode    string                 `json:"errorCode,omitempty"`
+	ErrorDetails string                 `json:"errorDetails,omitempty"`
+}
+
+type bulkResponse struct {
+	Flags []evaluation `json:"flags"`
+}
+
+type errorResponse struct {
+	ErrorCode    string `json:"errorCode,omitempty"`
+	ErrorDetails string `json:"errorDetails"`
+}
+
+type handler struct {
+	fs *flagsheet.FlagSheet
+}
+
+// NewHandler returns a handler for the OFREP endpoints under Path.
+func NewHandler(fs *flagsheet.FlagSheet) http.Handler {
+	return &handler{fs: fs}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var key string
+	switch {
+	case r.URL.Path == flagsPath:
+	case strings.HasPrefix(r.URL.Path, flagsPath+"/"):
+		key = strings.TrimPrefix(r.URL.Path, flagsPath+"/")
+		if key == "" || strings.Contains(key, "/") {
+			http.NotFound(w, r)
+			return
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{ErrorDetails: "method not allowed"})
+		return
+	}
+	ectx, err := readContext(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, evaluation{Key: key, ErrorCode: codeInvalidContext, ErrorDetails: err.Error()})
+		return
+	}
+	if key == "" {
+		h.evaluateAll(w, r, ectx)
+		return
+	}
+	h.evaluate(w, r, key, ectx)
+}
+
+func (h *handler) evaluate(w http.ResponseWriter, r *http.Request, key string, ectx flagsheet.EvaluationContext) {
+	d, err := h.fs.EvaluateDetail(key, ectx)
+	if err != nil {
+		switch {
+		case errors.Is(err, flagsheet.ErrFeatureNotFound):
+			writeJSON(w, http.StatusNotFound, evaluation{Key: key, ErrorCode: codeFlagNotFound, ErrorDetails: err.Error()})
+		case errors.Is(err, flagsheet.ErrNotReady):
+			writeJSON(w, http.StatusServiceUnavailable, errorResponse{ErrorDetails: err.Error()})
+		default:
+			writeJSON(w, http.StatusInternalServerError, errorResponse{ErrorDetails: err.Error()})
+		}
+		return
+	}
+	flagsheet.TraceEvaluation(r.Context(), d)
+	res := evaluationResult(d, nil)
+	if res.ErrorCode != "" {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{ErrorDetails: res.ErrorDetails})
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *handler) evaluateAll(w http.ResponseWriter, r *http.Request, ectx flagsheet.EvaluationContext) {
+	results, err := h.fs.EvaluateAll(ectx)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, flagsheet.ErrNotReady) {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, errorResponse{ErrorDetails: err.Error()})
+		return
+	}
+	res := bulkResponse{Flags: make([]evaluation, len(results))}
+	for i, result := range results {
+		if result.Err == nil {
+			flagsheet.TraceEvaluation(r.Context(), result.EvaluationDetail)
+		}
+		res.Flags[i] = evaluationResult(result.EvaluationDetail, result.Err)
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{ErrorDetails: err.Error()})
+		return
+	}
+	// The ETag is a hash of the response, as it depends on both the
+	// configuration and the context.
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// evaluationResult converts a detail, or the error that replaced it, to the
+// OFREP format.
+func evaluationResult(d flagsheet.EvaluationDetail, err error) evaluation {
+	if err != nil {
+		code := codeGeneral
+		if errors.Is(err, flagsheet.ErrFeatureNotFound) {
+			code = codeFlagNotFound
+		}
+		return evaluation{Key: d.Key, ErrorCode: code, ErrorDetails: err.Error()}
+	}
+	v, err := d.Typed()
+	if err != nil {
+		return evaluation{Key: d.Key, ErrorCode: codeParseError, ErrorDetails: err.Error()}
+	}
+	res := evaluation{
+		Key:     d.Key,
+		Value:   v,
+		Reason:  reasons[d.Reason],
+		Variant: string(d.Value),
+		Metadata: map[string]interface{}{
+			"layer":           d.Layer,
+			"layerVersion":    d.LayerVersion,
+			"bucket":          d.Bucket,
+			"snapshotVersion": d.SnapshotVersion,
+		},
+	}
+	if d.Rule != "" {
+		res.Metadata["rule"] = d.Rule
+	}
+	if v == nil {
+		res.Reason = reasons[flagsheet.ReasonDefault]
+	}
+	return res
+}
+
+// readContext reads the evaluation context from the request body. The
+// targeting key is the entity id, and the other fields are attributes.
+// An empty body is an empty context.
+func readContext(r *http.Request) (flagsheet.EvaluationContext, error) {
+	var ectx flagsheet.EvaluationContext
+	var req request
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil && err != io.EOF {
+		return ectx, fmt.Errorf("invalid request body: %v", err)
+	}
+	for k, v := range req.Context {
+		if k == "targetingKey" {
+			id, ok := v.(string)
+			if !ok {
+				return ectx, errors.New("targetingKey must be a string")
+			}
+			if id != "" {
+				ectx.ID = &id
+			}
+			continue
+		}
+		if ectx.Attributes == nil {
+			ectx.Attributes = make(map[string]interface{}, len(req.Context))
+		}
+		ectx.Attributes[k] = v
+	}
+	return ectx, nil
+}
+
+// etagMatch reports whether an If-None-Match header matches etag.
+func etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
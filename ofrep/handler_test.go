@@ -0,0 +1,125 @@
+package ofrep_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stillmatic/flagsheet"
+	"github.com/stillmatic/flagsheet/ofrep"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	tables := &flagsheet.Tables{
+		Flags: [][]string{
+			{"Key", "Layer", "Value", "Weight", "Type", "Rule"},
+			{"enabled", "a", "true", "1000", "bool", ""},
+			{"limit", "b", "42", "1000", "int", ""},
+			{"limit", "b", "100", "1000", "int", "country == US"},
+			{"partial", "c", "7", "1", "int", ""},
+		},
+		Layers: [][]string{{"Layer", "Version"}, {"a", "1"}, {"b", "1"}, {"c", "1"}},
+	}
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.Handle(ofrep.Path, ofrep.NewHandler(fs))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func post(t *testing.T, url, body string, header http.Header) (*http.Response, map[string]interface{}) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	assert.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	var msg map[string]interface{}
+	if res.StatusCode != http.StatusNotModified {
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&msg))
+	}
+	return res, msg
+}
+
+func TestEvaluateFlag(t *testing.T) {
+	ts := newTestServer(t)
+	url := ts.URL + "/ofrep/v1/evaluate/flags/"
+
+	res, msg := post(t, url+"enabled", `{"context": {"targetingKey": "my_id"}}`, nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "enabled", msg["key"])
+	assert.Equal(t, true, msg["value"])
+	assert.Equal(t, "SPLIT", msg["reason"])
+	assert.Equal(t, "true", msg["variant"])
+	assert.Equal(t, "a", msg["metadata"].(map[string]interface{})["layer"])
+
+	res, msg = post(t, url+"limit", `{"context": {"targetingKey": "my_id", "country": "US"}}`, nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, float64(100), msg["value"])
+	assert.Equal(t, "TARGETING_MATCH", msg["reason"])
+	assert.Equal(t, "country == US", msg["metadata"].(map[string]interface{})["rule"])
+
+	// empty variants leave the value to the client's default
+	res, msg = post(t, url+"partial", `{"context": {"targetingKey": "my_id"}}`, nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.NotContains(t, msg, "value")
+	assert.Equal(t, "DEFAULT", msg["reason"])
+
+	res, msg = post(t, url+"missing", `{"context": {}}`, nil)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	assert.Equal(t, "FLAG_NOT_FOUND", msg["errorCode"])
+
+	res, msg = post(t, url+"enabled", `{"context": {"targetingKey": 1}}`, nil)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, "INVALID_CONTEXT", msg["errorCode"])
+	res, _ = post(t, url+"enabled", `{`, nil)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err := http.Get(url + "enabled")
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+	res, err = http.Post(url+"a/b", "application/json", nil)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestEvaluateFlags(t *testing.T) {
+	ts := newTestServer(t)
+	url := ts.URL + "/ofrep/v1/evaluate/flags"
+	body := `{"context": {"targetingKey": "my_id", "country": "US"}}`
+
+	res, msg := post(t, url, body, nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	flags := msg["flags"].([]interface{})
+	assert.Len(t, flags, 3)
+	keys := make([]string, len(flags))
+	for i, flag := range flags {
+		keys[i] = flag.(map[string]interface{})["key"].(string)
+	}
+	assert.Equal(t, []string{"enabled", "limit", "partial"}, keys)
+	assert.Equal(t, float64(100), flags[1].(map[string]interface{})["value"])
+
+	etag := res.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+	res, _ = post(t, url, body, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, res.StatusCode)
+	assert.Equal(t, etag, res.Header.Get("ETag"))
+
+	// a different context gets different values, so the ETag doesn't match
+	res, msg = post(t, url, `{"context": {"targetingKey": "my_id"}}`, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.NotEqual(t, etag, res.Header.Get("ETag"))
+	assert.Equal(t, float64(42), msg["flags"].([]interface{})[1].(map[string]interface{})["value"])
+}
@@ -0,0 +1,169 @@
+// Package openfeature is an OpenFeature provider for flagsheet, so that
+// flags can be evaluated through the OpenFeature SDK:
+//
+//	fs, err := flagsheet.NewFlagSheet(ctx, source, 10*time.Second)
+//	openfeature.SetProvider(flagsheetof.NewProvider(fs))
+//	client := openfeature.NewClient("my-app")
+//	enabled, err := client.BooleanValue(ctx, "new_checkout", false,
+//		openfeature.NewEvaluationContext(userID, map[string]interface{}{"country": "US"}))
+//
+// The targeting key is the entity id used for bucketing, and the other
+// attributes are matched against targeting rules. Without a targeting key,
+// entities get a random bucket, just like a nil id.
+package openfeature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	of "github.com/open-feature/go-sdk/openfeature"
+	"github.com/stillmatic/flagsheet"
+)
+
+// Provider resolves OpenFeature flags with a FlagSheet or a FlagClient.
+type Provider struct {
+	evaluate func(ctx context.Context, key string, ectx flagsheet.EvaluationContext) (flagsheet.EvaluationDetail, error)
+}
+
+var _ of.FeatureProvider = (*Provider)(nil)
+
+// NewProvider returns a provider that evaluates flags in process.
+func NewProvider(fs *flagsheet.FlagSheet) *Provider {
+	return &Provider{
+		evaluate: func(_ context.Context, key string, ectx flagsheet.EvaluationContext) (flagsheet.EvaluationDetail, error) {
+			return fs.EvaluateDetail(key, ectx)
+		},
+	}
+}
+
+// NewClientProvider returns a provider that evaluates flags with a
+// FlagClient, remotely or locally depending on how it was created.
+func NewClientProvider(client *flagsheet.FlagClient) *Provider {
+	return &Provider{evaluate: client.EvaluateDetail}
+}
+
+func (p *Provider) Metadata() of.Metadata {
+	return of.Metadata{Name: "flagsheet"}
+}
+
+func (p *Provider) Hooks() []of.Hook {
+	return nil
+}
+
+func (p *Provider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx of.FlattenedContext) of.BoolResolutionDetail {
+	v, res := p.resolve(ctx, flag, flagsheet.TypeBool, evalCtx)
+	if b, ok := v.(bool); ok {
+		return of.BoolResolutionDetail{Value: b, ProviderResolutionDetail: res}
+	}
+	return of.BoolResolutionDetail{Value: defaultValue, ProviderResolutionDetail: res}
+}
+
+func (p *Provider) StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx of.FlattenedContext) of.StringResolutionDetail {
+	v, res := p.resolve(ctx, flag, flagsheet.TypeString, evalCtx)
+	if s, ok := v.(string); ok {
+		return of.StringResolutionDetail{Value: s, ProviderResolutionDetail: res}
+	}
+	return of.StringResolutionDetail{Value: defaultValue, ProviderResolutionDetail: res}
+}
+
+func (p *Provider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx of.FlattenedContext) of.FloatResolutionDetail {
+	v, res := p.resolve(ctx, flag, flagsheet.TypeFloat, evalCtx)
+	if f, ok := v.(float64); ok {
+		return of.FloatResolutionDetail{Value: f, ProviderResolutionDetail: res}
+	}
+	return of.FloatResolutionDetail{Value: defaultValue, ProviderResolutionDetail: res}
+}
+
+func (p *Provider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx of.FlattenedContext) of.IntResolutionDetail {
+	v, res := p.resolve(ctx, flag, flagsheet.TypeInt, evalCtx)
+	if i, ok := v.(int64); ok {
+		return of.IntResolutionDetail{Value: i, ProviderResolutionDetail: res}
+	}
+	return of.IntResolutionDetail{Value: defaultValue, ProviderResolutionDetail: res}
+}
+
+func (p *Provider) ObjectEvaluation(ctx context.Context, flag string, defaultValue interface{}, evalCtx of.FlattenedContext) of.InterfaceResolutionDetail {
+	v, res := p.resolve(ctx, flag, flagsheet.TypeJSON, evalCtx)
+	if v != nil {
+		return of.InterfaceResolutionDetail{Value: v, ProviderResolutionDetail: res}
+	}
+	return of.InterfaceResolutionDetail{Value: defaultValue, ProviderResolutionDetail: res}
+}
+
+var reasons = map[flagsheet.Reason]of.Reason{
+	flagsheet.ReasonBucket:         of.SplitReason,
+	flagsheet.ReasonTargetingMatch: of.TargetingMatchReason,
+	flagsheet.ReasonOverride:       of.TargetingMatchReason,
+	flagsheet.ReasonDefault:        of.DefaultReason,
+	flagsheet.ReasonError:          of.ErrorReason,
+}
+
+// resolve evaluates a flag and converts its value to want. The value is nil
+// if the caller's default should be used, e.g. for an empty variant.
+func (p *Provider) resolve(ctx context.Context, flag string, want flagsheet.VariantType, evalCtx of.FlattenedContext) (interface{}, of.ProviderResolutionDetail) {
+	d, err := p.evaluate(ctx, flag, evaluationContext(evalCtx))
+	if err != nil {
+		return nil, errorDetail(resolutionError(err))
+	}
+	res := of.ProviderResolutionDetail{
+		Reason:  reasons[d.Reason],
+		Variant: string(d.Value),
+		FlagMetadata: of.FlagMetadata{
+			"layer":           d.Layer,
+			"layerVersion":    d.LayerVersion,
+			"bucket":          d.Bucket,
+			"rule":            d.Rule,
+			"snapshotVersion": d.SnapshotVersion,
+		},
+	}
+	if d.Type != "" && d.Type != flagsheet.TypeString && d.Type != want {
+		return nil, errorDetail(of.NewTypeMismatchResolutionError(
+			fmt.Sprintf("flag %s has type %s, not %s", flag, d.Type, want)))
+	}
+	v, err := d.As(want)
+	if err != nil {
+		return nil, errorDetail(of.NewParseErrorResolutionError(err.Error()))
+	}
+	if v == nil {
+		// empty variants fall back to the caller's default
+		res.Reason = of.DefaultReason
+	}
+	return v, res
+}
+
+func errorDetail(rerr of.ResolutionError) of.ProviderResolutionDetail {
+	return of.ProviderResolutionDetail{
+		ResolutionError: rerr,
+		Reason:          of.ErrorReason,
+	}
+}
+
+func resolutionError(err error) of.ResolutionError {
+	switch {
+	case errors.Is(err, flagsheet.ErrFeatureNotFound):
+		return of.NewFlagNotFoundResolutionError(err.Error())
+	case errors.Is(err, flagsheet.ErrNotReady):
+		return of.NewProviderNotReadyResolutionError(err.Error())
+	}
+	return of.NewGeneralResolutionError(err.Error())
+}
+
+// evaluationContext maps the targeting key to the entity id, and the other
+// attributes to targeting attributes.
+func evaluationContext(evalCtx of.FlattenedContext) flagsheet.EvaluationContext {
+	var ectx flagsheet.EvaluationContext
+	for k, v := range evalCtx {
+		if k == of.TargetingKey {
+			if id, ok := v.(string); ok && id != "" {
+				ectx.ID = &id
+			}
+			continue
+		}
+		if ectx.Attributes == nil {
+			ectx.Attributes = make(map[string]interface{}, len(evalCtx))
+		}
+		ectx.Attributes[k] = v
+	}
+	return ectx
+}
@@ -0,0 +1,144 @@
+package openfeature_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bufbuild/connect-go"
+	of "github.com/open-feature/go-sdk/openfeature"
+	"github.com/stillmatic/flagsheet"
+	flagsheetv1 "github.com/stillmatic/flagsheet/gen/flagsheet/v1"
+	"github.com/stillmatic/flagsheet/gen/flagsheet/v1/flagsheetv1connect"
+	flagsheetof "github.com/stillmatic/flagsheet/openfeature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvider(t *testing.T) {
+	tables := &flagsheet.Tables{
+		Flags: [][]string{
+			{"Key", "Layer", "Value", "Weight", "Type"},
+			{"enabled", "a", "true", "1000", "bool"},
+			{"limit", "b", "42", "1000", "int"},
+			{"ratio", "c", "0.5", "1000", "float"},
+			{"config", "d", `{"color": "red"}`, "1000", "json"},
+			{"partial", "e", "7", "1", "int"},
+			{"untyped", "f", "false", "1000", ""},
+		},
+		Layers: [][]string{
+			{"Layer", "Version"},
+			{"a", "1"}, {"b", "1"}, {"c", "1"}, {"d", "1"}, {"e", "1"}, {"f", "1"},
+		},
+	}
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(tables), 0)
+	assert.NoError(t, err)
+	p := flagsheetof.NewProvider(fs)
+	assert.Equal(t, "flagsheet", p.Metadata().Name)
+	ctx := context.Background()
+	evalCtx := of.FlattenedContext{of.TargetingKey: "my_id", "country": "US"}
+
+	b := p.BooleanEvaluation(ctx, "enabled", false, evalCtx)
+	assert.NoError(t, b.Error())
+	assert.True(t, b.Value)
+	assert.Equal(t, of.SplitReason, b.Reason)
+	assert.Equal(t, "true", b.Variant)
+	assert.Equal(t, "a", b.FlagMetadata["layer"])
+	i := p.IntEvaluation(ctx, "limit", 1, evalCtx)
+	assert.NoError(t, i.Error())
+	assert.Equal(t, int64(42), i.Value)
+	f := p.FloatEvaluation(ctx, "ratio", 1, evalCtx)
+	assert.NoError(t, f.Error())
+	assert.Equal(t, 0.5, f.Value)
+	o := p.ObjectEvaluation(ctx, "config", nil, evalCtx)
+	assert.NoError(t, o.Error())
+	assert.Equal(t, map[string]interface{}{"color": "red"}, o.Value)
+	// string features can be read as any type that parses
+	b = p.BooleanEvaluation(ctx, "untyped", true, evalCtx)
+	assert.NoError(t, b.Error())
+	assert.False(t, b.Value)
+	s := p.StringEvaluation(ctx, "untyped", "", evalCtx)
+	assert.NoError(t, s.Error())
+	assert.Equal(t, "false", s.Value)
+
+	// empty variants fall back to the default
+	i = p.IntEvaluation(ctx, "partial", -1, evalCtx)
+	assert.NoError(t, i.Error())
+	assert.Equal(t, int64(-1), i.Value)
+	assert.Equal(t, of.DefaultReason, i.Reason)
+
+	// errors
+	b = p.BooleanEvaluation(ctx, "limit", true, evalCtx)
+	assert.ErrorContains(t, b.Error(), string(of.TypeMismatchCode))
+	assert.True(t, b.Value)
+	assert.Equal(t, of.ErrorReason, b.Reason)
+	i = p.IntEvaluation(ctx, "untyped", 3, evalCtx)
+	assert.ErrorContains(t, i.Error(), string(of.ParseErrorCode))
+	assert.Equal(t, int64(3), i.Value)
+	f = p.FloatEvaluation(ctx, "missing", 2.5, evalCtx)
+	assert.ErrorContains(t, f.Error(), string(of.FlagNotFoundCode))
+	assert.Equal(t, 2.5, f.Value)
+}
+
+func TestProviderSDK(t *testing.T) {
+	fs, err := flagsheet.NewFlagSheet(context.Background(), flagsheet.NewStaticSource(&flagsheet.Tables{
+		Flags: [][]string{
+			{"Key", "Layer", "Value", "Weight", "Type"},
+			{"enabled", "a", "true", "1000", "bool"},
+		},
+		Layers: [][]string{{"Layer", "Version"}, {"a", "1"}},
+	}), 0)
+	assert.NoError(t, err)
+	assert.NoError(t, of.SetProviderAndWait(flagsheetof.NewProvider(fs)))
+	t.Cleanup(func() { _ = of.SetProviderAndWait(of.NoopProvider{}) })
+
+	client := of.NewClient("test")
+	enabled, err := client.BooleanValue(context.Background(), "enabled", false,
+		of.NewEvaluationContext("my_id", map[string]interface{}{"country": "US"}))
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+	_, err = client.BooleanValue(context.Background(), "missing", false, of.EvaluationContext{})
+	assert.Error(t, err)
+}
+
+type fakeServer struct {
+	flagsheetv1connect.UnimplementedFlagSheetServiceHandler
+}
+
+func (fakeServer) Evaluate(
+	_ context.Context,
+	req *connect.Request[flagsheetv1.EvaluateRequest],
+) (*connect.Response[flagsheetv1.EvaluateResponse], error) {
+	switch req.Msg.Feature {
+	case "enabled":
+		return connect.NewResponse(&flagsheetv1.EvaluateResponse{
+			Variant: "true",
+			Value:   &flagsheetv1.EvaluateResponse_BoolValue{BoolValue: true},
+			Reason:  flagsheetv1.Reason_REASON_TARGETING_MATCH,
+		}), nil
+	case "unavailable":
+		return nil, connect.NewError(connect.CodeUnavailable, nil)
+	}
+	return nil, connect.NewError(connect.CodeNotFound, nil)
+}
+
+func TestClientProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle(flagsheetv1connect.NewFlagSheetServiceHandler(fakeServer{}))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	p := flagsheetof.NewClientProvider(flagsheet.NewFlagClient(ts.URL))
+	ctx := context.Background()
+	evalCtx := of.FlattenedContext{of.TargetingKey: "my_id"}
+
+	b := p.BooleanEvaluation(ctx, "enabled", false, evalCtx)
+	assert.NoError(t, b.Error())
+	assert.True(t, b.Value)
+	assert.Equal(t, of.TargetingMatchReason, b.Reason)
+	b = p.BooleanEvaluation(ctx, "missing", false, evalCtx)
+	assert.ErrorContains(t, b.Error(), string(of.FlagNotFoundCode))
+	b = p.BooleanEvaluation(ctx, "unavailable", false, evalCtx)
+	assert.ErrorContains(t, b.Error(), string(of.ProviderNotReadyCode))
+	s := p.StringEvaluation(ctx, "enabled", "", evalCtx)
+	assert.ErrorContains(t, s.Error(), string(of.TypeMismatchCode))
+}
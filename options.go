@@ -0,0 +1,68 @@
+package flagsheet
+
+import (
+	"math/rand"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option configures a FlagSheet.
+type Option func(*flagSheet)
+
+// WithSnapshotFile saves every newly loaded configuration to path.
+// If the first fetch fails when the FlagSheet is created, it starts from the
+// last saved configuration instead, and reports Stale until a fetch succeeds.
+func WithSnapshotFile(path string) Option {
+	return func(f *flagSheet) {
+		f.snapshotPath = path
+	}
+}
+
+// WithMaxStaleness makes Healthy report false once the last successful
+// refresh is older than d.
+func WithMaxStaleness(d time.Duration) Option {
+	return func(f *flagSheet) {
+		f.maxStaleness = d
+	}
+}
+
+// WithMaxBackoff caps how long the background refresh backs off after
+// consecutive failures. It defaults to 5 minutes.
+func WithMaxBackoff(d time.Duration) Option {
+	return func(f *flagSheet) {
+		f.maxBackoff = d
+	}
+}
+
+// WithMetrics reports evaluations and refreshes to m.
+func WithMetrics(m Metrics) Option {
+	return func(f *flagSheet) {
+		f.metrics = m
+	}
+}
+
+// WithExposureLogger logs the assignments of identified entities to
+// bucketed variants, e.g. to an ExposureBatcher, for experiment analysis.
+func WithExposureLogger(l ExposureLogger) Option {
+	return func(f *flagSheet) {
+		f.exposures = l
+	}
+}
+
+// WithRand picks the buckets of entities without an id with src, e.g. a
+// seeded source to make them reproducible in tests. Evaluations share src
+// under a lock, so they only follow its sequence when made one at a time.
+// By default, they use a lock-free pool of randomly seeded generators.
+func WithRand(src rand.Source) Option {
+	return func(f *flagSheet) {
+		f.rand = &lockedRand{r: rand.New(src)}
+	}
+}
+
+// WithTracerProvider traces refreshes with tp instead of the global tracer provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(f *flagSheet) {
+		f.tracer = tp.Tracer(tracerName)
+	}
+}
@@ -0,0 +1,51 @@
+package flagsheet
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// bucketRand picks the buckets of entities without an id.
+type bucketRand interface {
+	// Intn returns a uniformly distributed int in [0, n).
+	Intn(n int) int
+}
+
+// lockedRand serializes access to a rand.Source set with WithRand, as
+// sources are not safe for concurrent use.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (r *lockedRand) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Intn(n)
+}
+
+// pooledRand hands out generators from a sync.Pool, so that concurrent
+// evaluations don't contend on a lock like the global math/rand functions.
+type pooledRand struct {
+	pool sync.Pool
+}
+
+func newPooledRand() *pooledRand {
+	return &pooledRand{pool: sync.Pool{
+		New: func() interface{} {
+			// the global source is randomly seeded, and only used to seed
+			// new generators
+			return rand.New(rand.NewSource(rand.Int63()))
+		},
+	}}
+}
+
+func (p *pooledRand) Intn(n int) int {
+	r := p.pool.Get().(*rand.Rand)
+	v := r.Intn(n)
+	p.pool.Put(r)
+	return v
+}
+
+// defaultRand is shared by FlagSheets created without WithRand.
+var defaultRand = newPooledRand()
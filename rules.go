@@ -0,0 +1,216 @@
+package flagsheet
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// rule is a parsed targeting rule, a conjunction of conditions on attributes, e.g.
+//
+//	country in [US, CA] && app_version >= 3.2.0
+//
+// Supported operators are ==, !=, <, <=, >, >=, in and not in.
+// A condition on an attribute that is missing from the context never matches.
+type rule struct {
+	expr  string
+	conds []condition
+}
+
+type condition struct {
+	attr   string
+	op     string
+	values []string
+}
+
+var conditionRe = regexp.MustCompile(`^([A-Za-z_][\w.]*)\s*(==|!=|>=|<=|>|<|\s+not\s+in\s+|\s+in\s+)\s*(.+)$`)
+
+func parseRule(expr string) (*rule, error) {
+	r := &rule{expr: strings.TrimSpace(expr)}
+	for _, part := range strings.Split(r.expr, "&&") {
+		m := conditionRe.FindStringSubmatch(strings.TrimSpace(part))
+		if m == nil {
+			return nil, fmt.Errorf("invalid condition %q in rule %q", strings.TrimSpace(part), r.expr)
+		}
+		cond := condition{
+			attr: m[1],
+			op:   strings.Join(strings.Fields(m[2]), " "),
+		}
+		value := strings.TrimSpace(m[3])
+		if cond.op == "in" || cond.op == "not in" {
+			if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+				return nil, fmt.Errorf("%s needs a [list] in rule %q", cond.op, r.expr)
+			}
+			for _, v := range strings.Split(value[1:len(value)-1], ",") {
+				cond.values = append(cond.values, unquote(v))
+			}
+		} else {
+			cond.values = []string{unquote(value)}
+		}
+		r.conds = append(r.conds, cond)
+	}
+	return r, nil
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func (r *rule) String() string {
+	return r.expr
+}
+
+// Match reports whether every condition holds for the attributes.
+func (r *rule) Match(attrs map[string]interface{}) bool {
+	for _, cond := range r.conds {
+		if !cond.match(attrs) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c condition) match(attrs map[string]interface{}) bool {
+	attr, ok := attrs[c.attr]
+	if !ok || attr == nil {
+		return false
+	}
+	switch c.op {
+	case "in":
+		for _, v := range c.values {
+			if cmp, ok := compare(attr, v); ok && cmp == 0 {
+				return true
+			}
+		}
+		return false
+	case "not in":
+		for _, v := range c.values {
+			if cmp, ok := compare(attr, v); !ok || cmp == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	cmp, ok := compare(attr, c.values[0])
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// compare orders an attribute against a literal from a rule.
+// Numbers compare numerically and bools only by equality.
+// Strings that both look like versions (3.2.0) compare segment by segment,
+// other strings compare lexically.
+// It returns false if the two cannot be compared.
+func compare(attr interface{}, literal string) (int, bool) {
+	switch a := attr.(type) {
+	case bool:
+		b, err := strconv.ParseBool(literal)
+		if err != nil || a != b {
+			return 1, err == nil
+		}
+		return 0, true
+	case string:
+		if av, ok := parseVersion(a); ok {
+			if lv, ok := parseVersion(literal); ok {
+				return compareVersions(av, lv), true
+			}
+		}
+		return strings.Compare(a, literal), true
+	}
+	a, ok := toFloat(attr)
+	if !ok {
+		return 0, false
+	}
+	l, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return 0, false
+	}
+	switch {
+	case a < l:
+		return -1, true
+	case a > l:
+		return 1, true
+	}
+	return 0, true
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// parseVersion parses dot separated non-negative integers, with an optional leading v.
+func parseVersion(s string) ([]int, bool) {
+	s = strings.TrimPrefix(s, "v")
+	if s == "" {
+		return nil, false
+	}
+	parts := strings.Split(s, ".")
+	version := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		version[i] = n
+	}
+	return version, true
+}
+
+// compareVersions compares versions segment by segment, where missing segments count as 0.
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
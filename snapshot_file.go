@@ -0,0 +1,69 @@
+package flagsheet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotFormat is bumped whenever the snapshot file layout changes.
+const snapshotFormat = 1
+
+// snapshotFile is the on-disk last known good configuration.
+type snapshotFile struct {
+	Format  int       `json:"format"`
+	Version string    `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+	// ConfirmedAt is when the source last served these tables, which is
+	// later than SavedAt when refreshes found them unchanged.
+	ConfirmedAt time.Time `json:"confirmed_at"`
+	Tables      *Tables   `json:"tables"`
+}
+
+// saveSnapshot writes the tables to path, replacing the previous snapshot atomically.
+// savedAt is when the tables were first loaded, they are confirmed as of now.
+func saveSnapshot(path string, version string, savedAt time.Time, tables *Tables) error {
+	data, err := json.Marshal(snapshotFile{
+		Format:      snapshotFormat,
+		Version:     version,
+		SavedAt:     savedAt.UTC(),
+		ConfirmedAt: time.Now().UTC(),
+		Tables:      tables,
+	})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func loadSnapshot(path string) (*snapshotFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sf snapshotFile
+	if err := json.Unmarshal(data, &sf); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot file %s: %v", path, err)
+	}
+	if sf.Format != snapshotFormat {
+		return nil, fmt.Errorf("snapshot file %s has format %d, expected %d", path, sf.Format, snapshotFormat)
+	}
+	if sf.Tables == nil {
+		return nil, fmt.Errorf("snapshot file %s has no tables", path)
+	}
+	return &sf, nil
+}
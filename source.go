@@ -0,0 +1,162 @@
+package flagsheet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"gopkg.in/Iwark/spreadsheet.v2"
+)
+
+// Tables is the raw configuration a FlagSheet is built from.
+// Every table is a list of rows, and the first row of each table is a header
+// which is skipped when parsing.
+type Tables struct {
+	// Flags has Key, Layer, Value, Weight columns.
+	Flags [][]string `json:"flags"`
+	// Layers has Layer, Version columns.
+	Layers [][]string `json:"layers"`
+	// Overrides is optional and has Key, EntityID, Variant columns.
+	// An override pins an entity to a variant regardless of its bucket.
+	Overrides [][]string `json:"overrides,omitempty"`
+	// Token optionally identifies the revision of the tables.
+	// If a source returns the same non-empty token as the last applied fetch,
+	// the refresh is skipped.
+	Token string `json:"token,omitempty"`
+}
+
+// Source provides the tables that back a FlagSheet.
+type Source interface {
+	Fetch(ctx context.Context) (*Tables, error)
+}
+
+// Revisioner is implemented by sources that can cheaply tell whether their
+// contents changed. Before fetching, a refresh asks for the revision and skips
+// the fetch if it matches the revision of the loaded tables.
+// An empty revision means unknown and never matches.
+type Revisioner interface {
+	Revision(ctx context.Context) (string, error)
+}
+
+// SheetsSource reads flags from the first sheet and layers from the second
+// sheet of a Google spreadsheet. An optional third sheet holds overrides.
+//
+// Without a Drive client, every refresh downloads the spreadsheet, but
+// unchanged rows are not parsed again.
+type SheetsSource struct {
+	service *spreadsheet.Service
+	sheetID string
+	drive   *http.Client
+}
+
+func NewSheetsSource(service *spreadsheet.Service, sheetID string) *SheetsSource {
+	return &SheetsSource{
+		service: service,
+		sheetID: sheetID,
+	}
+}
+
+func (s *SheetsSource) Fetch(_ context.Context) (*Tables, error) {
+	sheet, err := s.service.FetchSpreadsheet(s.sheetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch spreadsheet: %v", err)
+	}
+	if len(sheet.Sheets) < 2 {
+		return nil, fmt.Errorf("spreadsheet %s must have a flags sheet and a layers sheet", s.sheetID)
+	}
+	tables := &Tables{
+		Flags:  cellValues(sheet.Sheets[0].Rows),
+		Layers: cellValues(sheet.Sheets[1].Rows),
+	}
+	if len(sheet.Sheets) > 2 {
+		tables.Overrides = cellValues(sheet.Sheets[2].Rows)
+	}
+	return tables, nil
+}
+
+// driveFilesURL is the Drive API files endpoint.
+const driveFilesURL = "https://www.googleapis.com/drive/v3/files/"
+
+// DriveScope is the OAuth scope needed by WithDriveClient.
+const DriveScope = "https://www.googleapis.com/auth/drive.metadata.readonly"
+
+// WithDriveClient makes the source check the spreadsheet's Drive revision
+// before each fetch, so refreshes skip the download while nothing changed.
+// The client must be authorized for DriveScope.
+func (s *SheetsSource) WithDriveClient(client *http.Client) *SheetsSource {
+	s.drive = client
+	return s
+}
+
+// Revision returns the Drive version of the spreadsheet, which increases on every change.
+// It returns an empty revision without a Drive client.
+func (s *SheetsSource) Revision(ctx context.Context) (string, error) {
+	if s.drive == nil {
+		return "", nil
+	}
+	u := driveFilesURL + url.PathEscape(s.sheetID) + "?fields=version,modifiedTime&supportsAllDrives=true"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := s.drive.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch spreadsheet revision: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch spreadsheet revision: %s", res.Status)
+	}
+	var file struct {
+		Version      string `json:"version"`
+		ModifiedTime string `json:"modifiedTime"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&file); err != nil {
+		return "", fmt.Errorf("failed to decode spreadsheet revision: %v", err)
+	}
+	if file.Version == "" {
+		return "", nil
+	}
+	return file.Version + "@" + file.ModifiedTime, nil
+}
+
+func cellValues(rows [][]spreadsheet.Cell) [][]string {
+	values := make([][]string, len(rows))
+	for i, row := range rows {
+		values[i] = make([]string, len(row))
+		for j, cell := range row {
+			values[i][j] = cell.Value
+		}
+	}
+	return values
+}
+
+// StaticSource serves tables held in memory.
+// It is mostly useful for tests and for embedding a fixed configuration.
+type StaticSource struct {
+	mu     sync.RWMutex
+	tables *Tables
+}
+
+func NewStaticSource(tables *Tables) *StaticSource {
+	return &StaticSource{tables: tables}
+}
+
+// Set replaces the tables returned by subsequent fetches.
+func (s *StaticSource) Set(tables *Tables) {
+	s.mu.Lock()
+	s.tables = tables
+	s.mu.Unlock()
+}
+
+func (s *StaticSource) Fetch(_ context.Context) (*Tables, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.tables == nil {
+		return nil, fmt.Errorf("static source has no tables")
+	}
+	return s.tables, nil
+}
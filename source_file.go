@@ -0,0 +1,278 @@
+package flagsheet
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	flagsFileName     = "flags.csv"
+	layersFileName    = "layers.csv"
+	overridesFileName = "overrides.csv"
+)
+
+// FileSource reads tables from the local filesystem.
+//
+// The path is either a JSON or YAML file, or a directory holding one CSV file
+// per sheet tab (flags.csv, layers.csv and optionally overrides.csv), with a
+// header row just like the sheet.
+// A JSON or YAML file has a list of objects per table, keyed by lowercase column name:
+//
+//	flags:
+//	  - {key: my_key, layer: a, value: foo, weight: 250}
+//	layers:
+//	  - {layer: a, version: 1}
+//	overrides:
+//	  - {key: my_key, entity_id: qa_user, variant: bar}
+//
+// Values are read as written, e.g. 1.10 or 007, and maps and lists are
+// converted to JSON, for json features.
+//
+// The token is a hash of the file contents, so when FileSource backs a
+// FlagSheet with a refresh interval, the janitor polls the files and only
+// reloads the configuration once they change. Polling only stats the files,
+// see Revision.
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+// fileTables is the JSON and YAML file layout. Values are kept undecoded, so
+// that cells read the same as in the sheet, e.g. 1.10 stays "1.10".
+type fileTables[V any] struct {
+	Flags     []map[string]V `json:"flags" yaml:"flags"`
+	Layers    []map[string]V `json:"layers" yaml:"layers"`
+	Overrides []map[string]V `json:"overrides" yaml:"overrides"`
+}
+
+// rows converts the tables, with cell converting each value to its text.
+func (ft fileTables[V]) rows(cell func(V) (string, error)) (flags, layers, overrides [][]string, err error) {
+	if flags, err = objectRows(ft.Flags, flagColumns, cell); err != nil {
+		return nil, nil, nil, err
+	}
+	if layers, err = objectRows(ft.Layers, layerColumns, cell); err != nil {
+		return nil, nil, nil, err
+	}
+	if overrides, err = objectRows(ft.Overrides, overrideColumns, cell); err != nil {
+		return nil, nil, nil, err
+	}
+	return flags, layers, overrides, nil
+}
+
+var (
+	flagColumns     = []string{"key", "layer", "value", "weight"}
+	layerColumns    = []string{"layer", "version"}
+	overrideColumns = []string{"key", "entity_id", "variant"}
+)
+
+func (s *FileSource) Fetch(_ context.Context) (*Tables, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flag file: %v", err)
+	}
+	if info.IsDir() {
+		return s.fetchCSV()
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flag file: %v", err)
+	}
+	var flags, layers, overrides [][]string
+	switch ext := strings.ToLower(filepath.Ext(s.path)); ext {
+	case ".json":
+		var ft fileTables[json.RawMessage]
+		if err = json.Unmarshal(data, &ft); err == nil {
+			flags, layers, overrides, err = ft.rows(jsonCell)
+		}
+	case ".yaml", ".yml":
+		var ft fileTables[yaml.Node]
+		if err = yaml.Unmarshal(data, &ft); err == nil {
+			flags, layers, overrides, err = ft.rows(yamlCell)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported flag file extension %q - must be .json, .yaml or .yml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse flag file %s: %v", s.path, err)
+	}
+	return &Tables{
+		Flags:     flags,
+		Layers:    layers,
+		Overrides: overrides,
+		Token:     hashBytes(data),
+	}, nil
+}
+
+// Revision returns the size and modification time of the files, so that
+// polling doesn't need to read them while they are unchanged.
+func (s *FileSource) Revision(_ context.Context) (string, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read flag file: %v", err)
+	}
+	if !info.IsDir() {
+		return fileRevision(info), nil
+	}
+	var revs []string
+	for _, name := range []string{flagsFileName, layersFileName, overridesFileName} {
+		info, err := os.Stat(filepath.Join(s.path, name))
+		if errors.Is(err, fs.ErrNotExist) && name == overridesFileName {
+			revs = append(revs, "-")
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read flag file: %v", err)
+		}
+		revs = append(revs, fileRevision(info))
+	}
+	return strings.Join(revs, ","), nil
+}
+
+func fileRevision(info fs.FileInfo) string {
+	return fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano())
+}
+
+func (s *FileSource) fetchCSV() (*Tables, error) {
+	h := sha256.New()
+	readTable := func(name string) ([][]string, error) {
+		data, err := os.ReadFile(filepath.Join(s.path, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read flag file: %v", err)
+		}
+		h.Write(data)
+		r := csv.NewReader(bytes.NewReader(data))
+		// allow ragged rows, parsing checks for required columns
+		r.FieldsPerRecord = -1
+		rows, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", name, err)
+		}
+		return rows, nil
+	}
+	flags, err := readTable(flagsFileName)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := readTable(layersFileName)
+	if err != nil {
+		return nil, err
+	}
+	var overrides [][]string
+	if _, err := os.Stat(filepath.Join(s.path, overridesFileName)); err == nil {
+		overrides, err = readTable(overridesFileName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Tables{
+		Flags:     flags,
+		Layers:    layers,
+		Overrides: overrides,
+		Token:     hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// objectRows converts a list of objects to rows with a header row.
+// The required columns come first, in order, followed by any other keys sorted by name.
+func objectRows[V any](objs []map[string]V, required []string, cell func(V) (string, error)) ([][]string, error) {
+	header := append([]string{}, required...)
+	seen := make(map[string]bool)
+	for _, col := range required {
+		seen[col] = true
+	}
+	var extra []string
+	for _, obj := range objs {
+		for k := range obj {
+			if !seen[k] {
+				seen[k] = true
+				extra = append(extra, k)
+			}
+		}
+	}
+	sort.Strings(extra)
+	header = append(header, extra...)
+
+	rows := make([][]string, 0, len(objs)+1)
+	rows = append(rows, header)
+	for _, obj := range objs {
+		row := make([]string, len(header))
+		for i, col := range header {
+			v, ok := obj[col]
+			if !ok {
+				continue
+			}
+			text, err := cell(v)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %v", col, err)
+			}
+			row[i] = text
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// jsonCell returns the text of a JSON value: strings are unquoted, numbers
+// and bools are kept as written, and objects and lists are compacted.
+func jsonCell(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	switch {
+	case len(raw) == 0 || bytes.Equal(raw, []byte("null")):
+		return "", nil
+	case raw[0] == '"':
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	case raw[0] == '{' || raw[0] == '[':
+		var buf bytes.Buffer
+		err := json.Compact(&buf, raw)
+		return buf.String(), err
+	}
+	return string(raw), nil
+}
+
+// yamlCell returns the text of a YAML value: scalars are kept as written,
+// and maps and lists are converted to JSON.
+func yamlCell(node yaml.Node) (string, error) {
+	n := &node
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode {
+		if n.ShortTag() == "!!null" {
+			return "", nil
+		}
+		return n.Value, nil
+	}
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,157 @@
+package flagsheet_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stillmatic/flagsheet"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testFlagsJSON = `{
+	"flags": [
+		{"key": "my_key", "layer": "a", "value": "foo", "weight": 250},
+		{"key": "my_key", "layer": "a", "value": "bar", "weight": 750}
+	],
+	"layers": [{"layer": "a", "version": 1}]
+}`
+	testFlagsYAML = `flags:
+  - {key: my_key, layer: a, value: foo, weight: 250}
+  - {key: my_key, layer: a, value: bar, weight: 750}
+layers:
+  - {layer: a, version: 1}
+overrides:
+  - {key: my_key, entity_id: qa_user, variant: foo}
+`
+	testFlagsCSV = `Key,Layer,Value,Weight
+my_key,a,foo,250
+my_key,a,bar,750
+`
+	testLayersCSV = `Layer,Version
+a,1
+`
+	testOverridesCSV = `Key,EntityID,Variant
+my_key,qa_user,foo
+`
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+}
+
+func TestFileSource(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "flags.json"), testFlagsJSON)
+	writeFile(t, filepath.Join(dir, "flags.yaml"), testFlagsYAML)
+	csvDir := filepath.Join(dir, "csv")
+	assert.NoError(t, os.Mkdir(csvDir, 0o755))
+	writeFile(t, filepath.Join(csvDir, "flags.csv"), testFlagsCSV)
+	writeFile(t, filepath.Join(csvDir, "layers.csv"), testLayersCSV)
+	writeFile(t, filepath.Join(csvDir, "overrides.csv"), testOverridesCSV)
+
+	for _, path := range []string{"flags.json", "flags.yaml", "csv"} {
+		t.Run(path, func(t *testing.T) {
+			source := flagsheet.NewFileSource(filepath.Join(dir, path))
+			tables, err := source.Fetch(context.Background())
+			assert.NoError(t, err)
+			assert.NotEmpty(t, tables.Token)
+			assert.Equal(t, []string{"my_key", "a", "bar", "750"}, tables.Flags[2][:4])
+
+			fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0)
+			assert.NoError(t, err)
+			fv, err := fs.Evaluate("my_key", stringPtr("my_id"))
+			assert.NoError(t, err)
+			assert.Equal(t, "bar", string(fv))
+
+			if path != "flags.json" {
+				fv, err = fs.Evaluate("my_key", stringPtr("qa_user"))
+				assert.NoError(t, err)
+				assert.Equal(t, "foo", string(fv))
+			}
+		})
+	}
+
+	_, err := flagsheet.NewFileSource(filepath.Join(dir, "flags.toml")).Fetch(context.Background())
+	assert.Error(t, err)
+}
+
+func TestFileSourceReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	writeFile(t, path, testFlagsJSON)
+
+	source := flagsheet.NewFileSource(path)
+	rev, err := source.Revision(context.Background())
+	assert.NoError(t, err)
+	fs, err := flagsheet.NewFlagSheet(context.Background(), source, 10*time.Millisecond)
+	assert.NoError(t, err)
+	defer fs.Close(context.Background())
+	_, err = fs.Evaluate("new_key", stringPtr("my_id"))
+	assert.Error(t, err)
+
+	writeFile(t, path, `{
+	"flags": [{"key": "new_key", "layer": "a", "value": "baz", "weight": 1000}],
+	"layers": [{"layer": "a", "version": 1}]
+}`)
+	newRev, err := source.Revision(context.Background())
+	assert.NoError(t, err)
+	assert.NotEqual(t, rev, newRev)
+	assert.Eventually(t, func() bool {
+		fv, err := fs.Evaluate("new_key", stringPtr("my_id"))
+		return err == nil && fv == "baz"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestFileSourceValues(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "flags.yaml"), `flags:
+  - {key: version, layer: a, value: 1.10, weight: 1000}
+  - {key: code, layer: b, value: 007, weight: 1000}
+  - key: config
+    layer: c
+    value: {color: red, sizes: [1, 2]}
+    weight: 1000
+    type: json
+layers:
+  - {layer: a, version: 1}
+  - {layer: b, version: 1}
+  - {layer: c, version: 1}
+`)
+	writeFile(t, filepath.Join(dir, "flags.json"), `{
+	"flags": [
+		{"key": "version", "layer": "a", "value": 1.10, "weight": 1000},
+		{"key": "code", "layer": "b", "value": "007", "weight": 1000},
+		{"key": "config", "layer": "c", "value": {"color": "red", "sizes": [1, 2]}, "weight": 1000, "type": "json"}
+	],
+	"layers": [{"layer": "a", "version": 1}, {"layer": "b", "version": 1}, {"layer": "c", "version": 1}]
+}`)
+
+	for _, path := range []string{"flags.yaml", "flags.json"} {
+		t.Run(path, func(t *testing.T) {
+			source := flagsheet.NewFileSource(filepath.Join(dir, path))
+			tables, err := source.Fetch(context.Background())
+			assert.NoError(t, err)
+			// values read as written, not as decoded
+			assert.Equal(t, "1.10", tables.Flags[1][2])
+			assert.Equal(t, "007", tables.Flags[2][2])
+			assert.JSONEq(t, `{"color": "red", "sizes": [1, 2]}`, tables.Flags[3][2])
+
+			fs, err := flagsheet.NewFlagSheet(context.Background(), source, 0)
+			assert.NoError(t, err)
+			fv, err := fs.Evaluate("version", stringPtr("my_id"))
+			assert.NoError(t, err)
+			assert.Equal(t, "1.10", string(fv))
+			var cfg struct {
+				Color string
+				Sizes []int
+			}
+			assert.NoError(t, fs.EvaluateJSON("config", flagsheet.EvaluationContext{ID: stringPtr("my_id")}, &cfg))
+			assert.Equal(t, "red", cfg.Color)
+			assert.Equal(t, []int{1, 2}, cfg.Sizes)
+		})
+	}
+}
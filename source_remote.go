@@ -0,0 +1,72 @@
+package flagsheet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/bufbuild/connect-go"
+	otelconnect "github.com/bufbuild/connect-opentelemetry-go"
+	flagsheetv1 "github.com/stillmatic/flagsheet/gen/flagsheet/v1"
+	"github.com/stillmatic/flagsheet/gen/flagsheet/v1/flagsheetv1connect"
+)
+
+// RemoteSource reads the configuration of a flagsheet server with the
+// GetConfig RPC, so that features can be evaluated locally with exactly the
+// same bucketing as the server.
+//
+// The token is the server's configuration version, so unchanged
+// configurations are not parsed again.
+type RemoteSource struct {
+	flags flagsheetv1connect.FlagSheetServiceClient
+	// pushed is a config streamed by Watch, served by the next Fetch
+	// instead of downloading it.
+	pushed atomic.Pointer[Tables]
+}
+
+func NewRemoteSource(flagsURL string) *RemoteSource {
+	return &RemoteSource{
+		flags: flagsheetv1connect.NewFlagSheetServiceClient(
+			http.DefaultClient,
+			flagsURL,
+			connect.WithInterceptors(otelconnect.NewInterceptor()),
+		),
+	}
+}
+
+func (s *RemoteSource) Fetch(ctx context.Context) (*Tables, error) {
+	if tables := s.pushed.Swap(nil); tables != nil {
+		return tables, nil
+	}
+	res, err := s.flags.GetConfig(ctx, connect.NewRequest(&flagsheetv1.GetConfigRequest{}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config: %w", remoteError("", err))
+	}
+	return configTables(res.Msg.GetConfig()), nil
+}
+
+// push makes the next Fetch return tables.
+func (s *RemoteSource) push(tables *Tables) {
+	s.pushed.Store(tables)
+}
+
+// configTables converts a config from the wire format.
+func configTables(config *flagsheetv1.Config) *Tables {
+	rows := func(msgs []*flagsheetv1.Row) [][]string {
+		if len(msgs) == 0 {
+			return nil
+		}
+		table := make([][]string, len(msgs))
+		for i, row := range msgs {
+			table[i] = row.GetCells()
+		}
+		return table
+	}
+	return &Tables{
+		Flags:     rows(config.GetFlags()),
+		Layers:    rows(config.GetLayers()),
+		Overrides: rows(config.GetOverrides()),
+		Token:     config.GetVersion(),
+	}
+}
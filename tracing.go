@@ -0,0 +1,37 @@
+package flagsheet
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name of the library's spans.
+const tracerName = "github.com/stillmatic/flagsheet"
+
+// TraceEvaluation records an evaluation on the active span in ctx as a
+// feature_flag event, following the OpenTelemetry semantic conventions, so
+// that a trace shows which variants a request saw.
+// FlagClient and the server call it for you.
+func TraceEvaluation(ctx context.Context, d EvaluationDetail) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent("feature_flag", trace.WithAttributes(
+		attribute.String("feature_flag.key", d.Key),
+		attribute.String("feature_flag.provider_name", "flagsheet"),
+		attribute.String("feature_flag.variant", string(d.Value)),
+	))
+}
+
+// endSpan records err, if any, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,186 @@
+package flagsheet
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VariantType is the declared type of a feature's variants, set with the
+// optional Type column of the flags tab.
+type VariantType string
+
+const (
+	TypeString VariantType = "string"
+	TypeBool   VariantType = "bool"
+	TypeInt    VariantType = "int"
+	TypeFloat  VariantType = "float"
+	TypeJSON   VariantType = "json"
+)
+
+func parseVariantType(s string) (VariantType, error) {
+	switch t := VariantType(strings.ToLower(s)); t {
+	case "":
+		return TypeString, nil
+	case TypeString, TypeBool, TypeInt, TypeFloat, TypeJSON:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown type %q - must be string, bool, int, float or json", s)
+}
+
+// parseValue converts a variant to a Go value of the given type:
+// string, bool, int64, float64, or the decoded JSON value.
+func parseValue(t VariantType, fv FeatureValue) (interface{}, error) {
+	s := string(fv)
+	switch t {
+	case TypeBool:
+		return strconv.ParseBool(s)
+	case TypeInt:
+		return strconv.ParseInt(s, 10, 64)
+	case TypeFloat:
+		return strconv.ParseFloat(s, 64)
+	case TypeJSON:
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return s, nil
+}
+
+// Reason says where an evaluated value came from.
+type Reason string
+
+const (
+	// ReasonBucket means the entity's bucket in the layer decided the variant.
+	ReasonBucket Reason = "BUCKET"
+	// ReasonTargetingMatch means a targeting rule matched and the entity's
+	// bucket in the rule's variants decided the variant.
+	ReasonTargetingMatch Reason = "TARGETING_MATCH"
+	// ReasonOverride means the entity is pinned to the variant by an override.
+	ReasonOverride Reason = "OVERRIDE"
+	// ReasonDefault means the entity's bucket is not filled by the feature,
+	// so it gets the feature's default.
+	ReasonDefault Reason = "DEFAULT"
+	// ReasonError means the evaluation failed.
+	ReasonError Reason = "ERROR"
+)
+
+// EvaluationDetail is the result of evaluating a feature,
+// along with the metadata that explains how it was reached.
+type EvaluationDetail struct {
+	Key    string
+	Value  FeatureValue
+	Type   VariantType
+	Reason Reason
+	// Layer and LayerVersion identify the layer the feature belongs to.
+	Layer        string
+	LayerVersion int
+	// Bucket is the entity's bucket in the layer, or in the target if a rule
+	// matched, or -1 if no bucket was computed, e.g. because of an override.
+	Bucket int
+	// Rule is the targeting rule that matched, if any.
+	Rule string
+	// SnapshotVersion identifies the configuration that was evaluated.
+	SnapshotVersion string
+}
+
+// Typed returns the value converted to the feature's type.
+// Empty values, from buckets that no variant fills, are returned as nil.
+func (d EvaluationDetail) Typed() (interface{}, error) {
+	if d.Value == "" {
+		return nil, nil
+	}
+	return parseValue(d.Type, d.Value)
+}
+
+// As returns the value converted to want, or nil for an empty value.
+// String features can be read as any type, as long as the value parses,
+// while typed features can only be read as their own type.
+func (d EvaluationDetail) As(want VariantType) (interface{}, error) {
+	if d.Type != "" && d.Type != TypeString && d.Type != want {
+		return nil, fmt.Errorf("feature %s has type %s, not %s", d.Key, d.Type, want)
+	}
+	if d.Value == "" {
+		return nil, nil
+	}
+	v, err := parseValue(want, d.Value)
+	if err != nil {
+		return nil, fmt.Errorf("feature %s variant %q is not a %s: %v", d.Key, d.Value, want, err)
+	}
+	return v, nil
+}
+
+func (d EvaluationDetail) boolValue(def bool) (bool, error) {
+	v, err := d.As(TypeBool)
+	if err != nil || v == nil {
+		return def, err
+	}
+	return v.(bool), nil
+}
+
+func (d EvaluationDetail) intValue(def int64) (int64, error) {
+	v, err := d.As(TypeInt)
+	if err != nil || v == nil {
+		return def, err
+	}
+	return v.(int64), nil
+}
+
+func (d EvaluationDetail) floatValue(def float64) (float64, error) {
+	v, err := d.As(TypeFloat)
+	if err != nil || v == nil {
+		return def, err
+	}
+	return v.(float64), nil
+}
+
+func (d EvaluationDetail) jsonValue(into interface{}) error {
+	if _, err := d.As(TypeJSON); err != nil || d.Value == "" {
+		return err
+	}
+	return json.Unmarshal([]byte(d.Value), into)
+}
+
+// EvaluateBool evaluates a bool feature.
+// It returns def if the evaluation fails or lands on an empty bucket.
+func (f *flagSheet) EvaluateBool(key string, ectx EvaluationContext, def bool) (bool, error) {
+	d, err := f.EvaluateDetail(key, ectx)
+	if err != nil {
+		return def, err
+	}
+	return d.boolValue(def)
+}
+
+// EvaluateInt evaluates an int feature.
+// It returns def if the evaluation fails or lands on an empty bucket.
+func (f *flagSheet) EvaluateInt(key string, ectx EvaluationContext, def int64) (int64, error) {
+	d, err := f.EvaluateDetail(key, ectx)
+	if err != nil {
+		return def, err
+	}
+	return d.intValue(def)
+}
+
+// EvaluateFloat evaluates a float feature.
+// It returns def if the evaluation fails or lands on an empty bucket.
+func (f *flagSheet) EvaluateFloat(key string, ectx EvaluationContext, def float64) (float64, error) {
+	d, err := f.EvaluateDetail(key, ectx)
+	if err != nil {
+		return def, err
+	}
+	return d.floatValue(def)
+}
+
+// EvaluateJSON evaluates a json feature and decodes the variant into into.
+// The caller provides the default by filling into beforehand, which is left
+// untouched if the evaluation fails or lands on an empty bucket.
+func (f *flagSheet) EvaluateJSON(key string, ectx EvaluationContext, into interface{}) error {
+	d, err := f.EvaluateDetail(key, ectx)
+	if err != nil {
+		return err
+	}
+	return d.jsonValue(into)
+}